@@ -0,0 +1,96 @@
+package bufpool
+
+import "io"
+
+// CopyPath tells how Copy moved bytes.
+type CopyPath int
+
+const (
+	// CopyPathBuffer copied through a buffer taken from the pool.
+	CopyPathBuffer CopyPath = iota + 1
+	// CopyPathWriterTo delegated to src's WriteTo.
+	CopyPathWriterTo
+	// CopyPathReaderFrom delegated to dst's ReadFrom.
+	CopyPathReaderFrom
+)
+
+func (p CopyPath) String() string {
+	switch p {
+	case CopyPathBuffer:
+		return "buffer"
+	case CopyPathWriterTo:
+		return "WriterTo"
+	case CopyPathReaderFrom:
+		return "ReaderFrom"
+	default:
+		return "unknown"
+	}
+}
+
+const defaultCopyBufSize = 32 * 1024
+
+type CopyOptions struct {
+	// AllowWriterTo allows Copy to use src's WriteTo.
+	AllowWriterTo bool
+	// AllowReaderFrom allows Copy to use dst's ReadFrom.
+	// For *os.File, this is the path taking copy_file_range or splice on linux.
+	AllowReaderFrom bool
+	// BufSize is the size of the buffer taken from the pool. 32KiB if zero.
+	BufSize int
+}
+
+type CopyResult struct {
+	Written int64
+	Path    CopyPath
+}
+
+// Copy copies from src to dst through the default pool. See Pool.Copy.
+func Copy(dst io.Writer, src io.Reader, opts CopyOptions) (CopyResult, error) {
+	return defaultPool.Copy(dst, src, opts)
+}
+
+// Copy copies from src to dst like io.Copy, but lets the caller choose
+// whether WriteTo of src or ReadFrom of dst may be used, in this order.
+// io.CopyBuffer silently bypasses its buffer when they are implemented,
+// while Copy only takes the buffer from p when they are not allowed or not implemented.
+func (p *Pool) Copy(dst io.Writer, src io.Reader, opts CopyOptions) (CopyResult, error) {
+	if opts.AllowWriterTo {
+		if wt, ok := src.(io.WriterTo); ok {
+			n, err := wt.WriteTo(dst)
+			return CopyResult{Written: n, Path: CopyPathWriterTo}, err
+		}
+	}
+	if opts.AllowReaderFrom {
+		if rf, ok := dst.(io.ReaderFrom); ok {
+			n, err := rf.ReadFrom(src)
+			return CopyResult{Written: n, Path: CopyPathReaderFrom}, err
+		}
+	}
+
+	size := opts.BufSize
+	if size <= 0 {
+		size = defaultCopyBufSize
+	}
+	buf := p.Get(size)
+	defer p.Put(buf)
+	n, err := io.CopyBuffer(onlyWriter{dst}, onlyReader{src}, *buf)
+	return CopyResult{Written: n, Path: CopyPathBuffer}, err
+}
+
+// prevent WriteTo from being used
+type onlyReader struct {
+	r io.Reader
+}
+
+func (r onlyReader) Read(p []byte) (int, error) {
+	return r.r.Read(p)
+}
+
+// prevent ReadFrom from being used.
+type onlyWriter struct {
+	w io.Writer
+}
+
+func (w onlyWriter) Write(p []byte) (int, error) {
+	return w.w.Write(p)
+}
@@ -0,0 +1,101 @@
+//go:build bufpooldebug
+
+package bufpool
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"weak"
+)
+
+// Debug reports whether the package is built with the bufpooldebug tag.
+const Debug = true
+
+const poison = 0xde
+
+// tracker tracks buffers taken from and returned to a Pool.
+//
+// Returned buffers are poisoned and checked when taken again, to detect use after Put.
+// Returned buffers are only weakly referenced, and forgotten once sync.Pool drops them and they are collected.
+type tracker struct {
+	mu          sync.Mutex
+	outstanding map[*[]byte]string // buffer -> stack of Get
+	returned    map[weak.Pointer[[]byte]]returned
+}
+
+type returned struct {
+	stack   string // stack of Put
+	cleanup runtime.Cleanup
+}
+
+func (t *tracker) init() {
+	if t.outstanding == nil {
+		t.outstanding = make(map[*[]byte]string)
+		t.returned = make(map[weak.Pointer[[]byte]]returned)
+	}
+}
+
+func (t *tracker) forget(wp weak.Pointer[[]byte]) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.returned, wp)
+}
+
+// take records b as outstanding. reused is true if b came out of the pool.
+func (t *tracker) take(b *[]byte, reused bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.init()
+	if reused {
+		wp := weak.Make(b)
+		r := t.returned[wp]
+		r.cleanup.Stop()
+		delete(t.returned, wp)
+		for _, c := range (*b)[:cap(*b)] {
+			if c != poison {
+				panic(fmt.Sprintf("bufpool: buffer modified after Put at\n%s", r.stack))
+			}
+		}
+	}
+	t.outstanding[b] = string(debug.Stack())
+}
+
+// release untracks b. It returns false if b is already in the pool.
+// Buffers not taken by Get are released without complaint.
+func (t *tracker) release(b *[]byte) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.init()
+	if _, ok := t.returned[weak.Make(b)]; ok {
+		return false
+	}
+	delete(t.outstanding, b)
+	return true
+}
+
+// pool poisons b which is about to be pooled.
+func (t *tracker) pool(b *[]byte) {
+	s := (*b)[:cap(*b)]
+	for i := range s {
+		s[i] = poison
+	}
+	wp := weak.Make(b)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.returned[wp] = returned{
+		stack:   string(debug.Stack()),
+		cleanup: runtime.AddCleanup(b, t.forget, wp),
+	}
+}
+
+func (t *tracker) leaks() []Leak {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	leaks := make([]Leak, 0, len(t.outstanding))
+	for b, stack := range t.outstanding {
+		leaks = append(leaks, Leak{Cap: cap(*b), Stack: stack})
+	}
+	return leaks
+}
@@ -0,0 +1,52 @@
+//go:build bufpooldebug
+
+package bufpool
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLeaks(t *testing.T) {
+	var p Pool
+	b := p.Get(100)
+	leaks := p.Leaks()
+	if len(leaks) != 1 || leaks[0].Cap != MinSize || !strings.Contains(leaks[0].Stack, "TestLeaks") {
+		t.Fatalf("leaks = %+v", leaks)
+	}
+	p.Put(b)
+	if leaks := p.Leaks(); len(leaks) != 0 {
+		t.Fatalf("leaks after Put = %+v", leaks)
+	}
+}
+
+func TestRejectDoublePut(t *testing.T) {
+	var p Pool
+	b := p.Get(100)
+	p.Put(b)
+	p.Put(b)
+	if s := p.Stats(); s.Puts != 1 || s.RejectedDoublePut != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestPanicOnModifiedAfterPut(t *testing.T) {
+	var p Pool
+	b := p.Get(100)
+	p.Put(b)
+	(*b)[0] = 1
+
+	var got *[]byte
+	rec := func() (rec any) {
+		defer func() { rec = recover() }()
+		got = p.Get(100)
+		return nil
+	}()
+	if rec == nil {
+		// sync.Pool may drop b, e.g. randomly under the race detector.
+		t.Skipf("got another buffer: %p, want %p", got, b)
+	}
+	if msg, _ := rec.(string); !strings.Contains(msg, "modified after Put") {
+		t.Fatalf("recovered %v", rec)
+	}
+}
@@ -0,0 +1,21 @@
+package bufpool
+
+// Leak is a buffer taken by Get but not yet returned by Put.
+type Leak struct {
+	Cap int
+	// Stack is the stack trace of Get.
+	Stack string
+}
+
+// Leaks returns outstanding buffers of the default pool. See Pool.Leaks.
+func Leaks() []Leak {
+	return defaultPool.Leaks()
+}
+
+// Leaks returns buffers taken from p and not yet returned.
+// It is only tracked in the debug build, made with -tags bufpooldebug,
+// which also rejects double Put and panics on buffers modified after Put.
+// Otherwise Leaks always returns nil.
+func (p *Pool) Leaks() []Leak {
+	return p.tracker.leaks()
+}
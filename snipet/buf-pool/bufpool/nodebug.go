@@ -0,0 +1,13 @@
+//go:build !bufpooldebug
+
+package bufpool
+
+// Debug reports whether the package is built with the bufpooldebug tag.
+const Debug = false
+
+type tracker struct{}
+
+func (t *tracker) take(b *[]byte, reused bool) {}
+func (t *tracker) release(b *[]byte) bool      { return true }
+func (t *tracker) pool(b *[]byte)              {}
+func (t *tracker) leaks() []Leak               { return nil }
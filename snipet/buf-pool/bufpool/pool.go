@@ -0,0 +1,104 @@
+package bufpool
+
+import (
+	"math/bits"
+	"sync"
+)
+
+const (
+	MinSize = 512
+	MaxSize = 4 * 1024 * 1024
+
+	minShift   = 9 // log2(MinSize)
+	numClasses = 22 - minShift + 1
+)
+
+// Pool pools byte slices in power-of-two size classes from MinSize to MaxSize.
+//
+// The zero Pool is ready to use.
+type Pool struct {
+	classes  [numClasses]sync.Pool
+	counters counters
+	tracker  tracker
+}
+
+var defaultPool Pool
+
+// Get gets a slice from the default pool. See Pool.Get.
+func Get(n int) *[]byte {
+	return defaultPool.Get(n)
+}
+
+// Put puts b back to the default pool. See Pool.Put.
+func Put(b *[]byte) {
+	defaultPool.Put(b)
+}
+
+// Get returns a slice whose len is n and cap is the smallest size class fitting n.
+// Slices larger than MaxSize are allocated without pooling.
+func (p *Pool) Get(n int) *[]byte {
+	if n < 0 {
+		panic("bufpool: negative size")
+	}
+	p.counters.gets.Add(1)
+	if n > MaxSize {
+		p.counters.news.Add(1)
+		b := make([]byte, n)
+		p.tracker.take(&b, false)
+		return &b
+	}
+	idx := classOf(n)
+	if v := p.classes[idx].Get(); v != nil {
+		b := v.(*[]byte)
+		p.tracker.take(b, true)
+		*b = (*b)[:n]
+		return b
+	}
+	p.counters.news.Add(1)
+	b := make([]byte, n, classSize(idx))
+	p.tracker.take(&b, false)
+	return &b
+}
+
+// Put puts b back to the pool.
+// b may have any capacity: it goes to the largest size class not exceeding its capacity,
+// with its capacity cut down to the size of the class.
+// Slices smaller than MinSize or larger than MaxSize are rejected.
+// b must not be used after Put.
+func (p *Pool) Put(b *[]byte) {
+	if b == nil {
+		return
+	}
+	if !p.tracker.release(b) {
+		p.counters.doublePut.Add(1)
+		return
+	}
+	c := cap(*b)
+	if c < MinSize {
+		p.counters.tooSmall.Add(1)
+		return
+	}
+	if c > MaxSize {
+		// See https://golang.org/issue/23199
+		p.counters.oversized.Add(1)
+		return
+	}
+	p.counters.puts.Add(1)
+	idx := bits.Len(uint(c)) - 1 - minShift
+	size := classSize(idx)
+	*b = (*b)[:size:size]
+	p.tracker.pool(b)
+	p.classes[idx].Put(b)
+}
+
+// classOf returns index of the smallest class fitting n.
+func classOf(n int) int {
+	if n <= MinSize {
+		return 0
+	}
+	return bits.Len(uint(n-1)) - minShift
+}
+
+func classSize(idx int) int {
+	return MinSize << idx
+}
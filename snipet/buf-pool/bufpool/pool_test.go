@@ -0,0 +1,71 @@
+package bufpool
+
+import "testing"
+
+func TestClassOf(t *testing.T) {
+	for n, want := range map[int]int{
+		0:           MinSize,
+		1:           MinSize,
+		MinSize:     MinSize,
+		MinSize + 1: 2 * MinSize,
+		3000:        4096,
+		4096:        4096,
+		MaxSize:     MaxSize,
+	} {
+		if got := classSize(classOf(n)); got != want {
+			t.Errorf("class of %d = %d, want %d", n, got, want)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	var p Pool
+	b := p.Get(3000)
+	if len(*b) != 3000 || cap(*b) != 4096 {
+		t.Fatalf("len = %d, cap = %d", len(*b), cap(*b))
+	}
+	p.Put(b)
+
+	large := p.Get(MaxSize + 1)
+	if len(*large) != MaxSize+1 {
+		t.Fatalf("len = %d", len(*large))
+	}
+}
+
+func TestPutCutsCapacity(t *testing.T) {
+	var p Pool
+	b := make([]byte, 10, 3000)
+	p.Put(&b)
+	// the largest class not exceeding 3000.
+	if len(b) != 2048 || cap(b) != 2048 {
+		t.Fatalf("len = %d, cap = %d", len(b), cap(b))
+	}
+	if s := p.Stats(); s.Puts != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestPutRejects(t *testing.T) {
+	var p Pool
+	small := make([]byte, MinSize-1)
+	p.Put(&small)
+	oversized := make([]byte, MaxSize+1)
+	p.Put(&oversized)
+	p.Put(nil)
+	if s := p.Stats(); s.Puts != 0 || s.RejectedTooSmall != 1 || s.RejectedOversized != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+	if cap(small) != MinSize-1 || cap(oversized) != MaxSize+1 {
+		t.Fatal("rejected slices were modified")
+	}
+}
+
+func TestGetNegativePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("did not panic")
+		}
+	}()
+	var p Pool
+	p.Get(-1)
+}
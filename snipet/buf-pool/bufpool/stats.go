@@ -0,0 +1,51 @@
+package bufpool
+
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+type Stats struct {
+	Gets uint64 `json:"gets"`
+	// News is the number of Gets which allocated since the pool had no buffer.
+	News uint64 `json:"news"`
+	// Puts is the number of buffers accepted by Put.
+	Puts              uint64 `json:"puts"`
+	RejectedTooSmall  uint64 `json:"rejected_too_small"`
+	RejectedOversized uint64 `json:"rejected_oversized"`
+	// RejectedDoublePut is only counted in the debug build, see Leaks.
+	// The buffer is not pooled twice, so it is never handed out to two callers.
+	RejectedDoublePut uint64 `json:"rejected_double_put"`
+}
+
+type counters struct {
+	gets, news, puts               atomic.Uint64
+	tooSmall, oversized, doublePut atomic.Uint64
+}
+
+// ReadStats returns stats of the default pool. See Pool.Stats.
+func ReadStats() Stats {
+	return defaultPool.Stats()
+}
+
+// Publish publishes stats of the default pool. See Pool.Publish.
+func Publish(name string) {
+	defaultPool.Publish(name)
+}
+
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Gets:              p.counters.gets.Load(),
+		News:              p.counters.news.Load(),
+		Puts:              p.counters.puts.Load(),
+		RejectedTooSmall:  p.counters.tooSmall.Load(),
+		RejectedOversized: p.counters.oversized.Load(),
+		RejectedDoublePut: p.counters.doublePut.Load(),
+	}
+}
+
+// Publish publishes stats of p as an expvar variable under name.
+// Like expvar.Publish, it panics if name is already registered.
+func (p *Pool) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return p.Stats() }))
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"buf-pool/bufpool"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"text/tabwriter"
+)
+
+const bufSize = 8 * 1024
+
+var bytesPool = &sync.Pool{
+	New: func() any {
+		b := make([]byte, bufSize)
+		return &b
+	},
+}
+
+// benchSingle gets n bytes from a single-size pool, allocating if n does not fit.
+func benchSingle(n int) func(b *testing.B) {
+	return func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if n > bufSize {
+				buf := make([]byte, n)
+				sink(buf)
+				continue
+			}
+			buf := bytesPool.Get().(*[]byte)
+			sink((*buf)[:n])
+			bytesPool.Put(buf)
+		}
+	}
+}
+
+func benchClasses(n int) func(b *testing.B) {
+	return func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			buf := bufpool.Get(n)
+			sink(*buf)
+			bufpool.Put(buf)
+		}
+	}
+}
+
+var sunk []byte
+
+//go:noinline
+func sink(b []byte) {
+	sunk = b
+}
+
+func main() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "size\tsingle-size sync.Pool\tsize classes\t")
+	for _, n := range []int{512, 4 * 1024, 8 * 1024, 64 * 1024, 1024 * 1024, 4 * 1024 * 1024} {
+		fmt.Fprintf(w, "%d\t%s\t%s\t\n", n, result(benchSingle(n)), result(benchClasses(n)))
+	}
+	_ = w.Flush()
+}
+
+func result(fn func(b *testing.B)) string {
+	r := testing.Benchmark(fn)
+	return fmt.Sprintf("%d ns/op %d allocs/op", r.NsPerOp(), r.AllocsPerOp())
+}
@@ -0,0 +1,3 @@
+module buf-pool
+
+go 1.24.0
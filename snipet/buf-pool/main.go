@@ -1,6 +1,7 @@
 package main
 
 import (
+	"buf-pool/bufpool"
 	"bytes"
 	"fmt"
 	"io"
@@ -84,4 +85,26 @@ func main() {
 	fmt.Printf("output: %s\n", buf.String())                                  // output: foobarbaz
 	fmt.Printf("buf: len: %d, cap: %d\n", len(*bytesSlice), cap(*bytesSlice)) // buf: len: 8192, cap: 8192
 	fmt.Printf("content: %s\n", *bytesSlice)                                  // content: foobarbaz
+
+	sized := bufpool.Get(10_000)
+	defer bufpool.Put(sized)
+	fmt.Printf("sized: len: %d, cap: %d\n", len(*sized), cap(*sized)) // sized: len: 10000, cap: 16384
+
+	_ = bufpool.Get(1024) // never Put
+	fmt.Printf("stats: %+v\n", bufpool.ReadStats())
+	// stats: {Gets:2 News:2 Puts:0 RejectedTooSmall:0 RejectedOversized:0 RejectedDoublePut:0}
+	fmt.Printf("debug = %t, leaks = %d\n", bufpool.Debug, len(bufpool.Leaks()))
+	// debug = false, leaks = 0
+	// with -tags bufpooldebug: debug = true, leaks = 2
+
+	for _, opts := range []bufpool.CopyOptions{{}, {AllowWriterTo: true}, {AllowReaderFrom: true}} {
+		var out bytes.Buffer
+		result, err := bufpool.Copy(&out, bytes.NewReader([]byte(`foobarbaz`)), opts)
+		fmt.Printf("copy: %+v, written = %d, path = %s, err = %v\n", opts, result.Written, result.Path, err)
+	}
+	/*
+		copy: {AllowWriterTo:false AllowReaderFrom:false BufSize:0}, written = 9, path = buffer, err = <nil>
+		copy: {AllowWriterTo:true AllowReaderFrom:false BufSize:0}, written = 9, path = WriterTo, err = <nil>
+		copy: {AllowWriterTo:false AllowReaderFrom:true BufSize:0}, written = 9, path = ReaderFrom, err = <nil>
+	*/
 }
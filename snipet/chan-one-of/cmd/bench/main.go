@@ -0,0 +1,142 @@
+package main
+
+import (
+	"chan-one-of/oneof"
+	"fmt"
+	"os"
+	"testing"
+	"text/tabwriter"
+)
+
+type fixedSendFunc struct {
+	arity int
+	fn    func(chans []chan<- int, v int, cancel <-chan struct{}) (int, bool)
+}
+
+type fixedRecvFunc struct {
+	arity int
+	fn    func(chans []<-chan int, cancel <-chan struct{}) (int, int, bool, bool)
+}
+
+// benchSend measures a send to n channels each of which has a room.
+func benchSend(n int, send func(chans []chan<- int, v int, cancel <-chan struct{}) (int, bool)) func(b *testing.B) {
+	return func(b *testing.B) {
+		chans := make([]chan int, n)
+		sendChans := make([]chan<- int, n)
+		for i := range chans {
+			chans[i] = make(chan int, 1)
+			sendChans[i] = chans[i]
+		}
+		b.ResetTimer()
+		for i := range b.N {
+			chosen, _ := send(sendChans, i, nil)
+			<-chans[chosen]
+		}
+	}
+}
+
+// benchRecv measures a receive from n channels one of which has a value.
+func benchRecv(n int, recv func(chans []<-chan int, cancel <-chan struct{}) (int, int, bool, bool)) func(b *testing.B) {
+	return func(b *testing.B) {
+		chans := make([]chan int, n)
+		recvChans := make([]<-chan int, n)
+		for i := range chans {
+			chans[i] = make(chan int, 1)
+			recvChans[i] = chans[i]
+		}
+		b.ResetTimer()
+		for i := range b.N {
+			chans[i%n] <- i
+			_, _, _, _ = recv(recvChans, nil)
+		}
+	}
+}
+
+// benchRecvEach measures a value received by RecvEachWith from n channels each fed by its own goroutine.
+func benchRecvEach(n int, opts oneof.Options) func(b *testing.B) {
+	return func(b *testing.B) {
+		chans := make([]<-chan int, n)
+		feeds := make([]chan int, n)
+		for i := range chans {
+			feeds[i] = make(chan int, 1)
+			chans[i] = feeds[i]
+		}
+		b.ResetTimer()
+		for i, feed := range feeds {
+			count := b.N / n
+			if i < b.N%n {
+				count++
+			}
+			go func() {
+				for j := range count {
+					feed <- j
+				}
+				close(feed)
+			}()
+		}
+		_, _ = oneof.RecvEachWith(chans, func(int, int) {}, nil, opts)
+	}
+}
+
+var sizes = []int{4, 5, 8, 9, 16, 17, 64, 65, 128, 256, 512, 1024}
+
+func main() {
+	byArity()
+	fmt.Println()
+	bySize()
+}
+
+func byArity() {
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "arity\tfixed send\treflect send\tfixed recv\treflect recv\t")
+	for i, s := range fixedSend {
+		r := fixedRecv[i]
+		fmt.Fprintf(
+			w,
+			"%d\t%s\t%s\t%s\t%s\t\n",
+			s.arity,
+			nsPerOp(benchSend(s.arity, s.fn)),
+			nsPerOp(benchSend(s.arity, oneof.SendN[[]chan<- int])),
+			nsPerOp(benchRecv(r.arity, r.fn)),
+			nsPerOp(benchRecv(r.arity, oneof.RecvN[[]<-chan int])),
+		)
+	}
+	_ = w.Flush()
+}
+
+func bySize() {
+	fixed := oneof.Options{Strategy: oneof.StrategyFixed}
+	reflect := oneof.Options{Strategy: oneof.StrategyReflect}
+	fanIn := oneof.Options{Strategy: oneof.StrategyFanIn}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 8, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "size\tfixed recv\treflect recv\tfixed recv each\treflect recv each\tfan-in recv each\t")
+	for _, n := range sizes {
+		fixedRecv, fixedRecvEach := "-", "-"
+		if n <= oneof.MaxFixedArity {
+			fixedRecv = nsPerOp(benchRecv(n, withOpts(fixed)))
+			fixedRecvEach = nsPerOp(benchRecvEach(n, fixed))
+		}
+		fmt.Fprintf(
+			w,
+			"%d\t%s\t%s\t%s\t%s\t%s\t\n",
+			n,
+			fixedRecv,
+			nsPerOp(benchRecv(n, withOpts(reflect))),
+			fixedRecvEach,
+			nsPerOp(benchRecvEach(n, reflect)),
+			nsPerOp(benchRecvEach(n, fanIn)),
+		)
+	}
+	_ = w.Flush()
+}
+
+func withOpts(opts oneof.Options) func(chans []<-chan int, cancel <-chan struct{}) (int, int, bool, bool) {
+	return func(chans []<-chan int, cancel <-chan struct{}) (int, int, bool, bool) {
+		return oneof.RecvWith(chans, cancel, opts)
+	}
+}
+
+func nsPerOp(fn func(b *testing.B)) string {
+	return fmt.Sprintf("%d ns/op", testing.Benchmark(fn).NsPerOp())
+}
@@ -0,0 +1,41 @@
+// Code generated by genselect -arity 4,8,16,32,64 -o select_gen.go -bench ../cmd/bench/select_gen.go. DO NOT EDIT.
+
+package main
+
+import "chan-one-of/oneof"
+
+var fixedSend = []fixedSendFunc{
+	{4, func(chans []chan<- int, v int, cancel <-chan struct{}) (int, bool) {
+		return oneof.Send4([4]chan<- int(chans), v, cancel)
+	}},
+	{8, func(chans []chan<- int, v int, cancel <-chan struct{}) (int, bool) {
+		return oneof.Send8([8]chan<- int(chans), v, cancel)
+	}},
+	{16, func(chans []chan<- int, v int, cancel <-chan struct{}) (int, bool) {
+		return oneof.Send16([16]chan<- int(chans), v, cancel)
+	}},
+	{32, func(chans []chan<- int, v int, cancel <-chan struct{}) (int, bool) {
+		return oneof.Send32([32]chan<- int(chans), v, cancel)
+	}},
+	{64, func(chans []chan<- int, v int, cancel <-chan struct{}) (int, bool) {
+		return oneof.Send64([64]chan<- int(chans), v, cancel)
+	}},
+}
+
+var fixedRecv = []fixedRecvFunc{
+	{4, func(chans []<-chan int, cancel <-chan struct{}) (int, int, bool, bool) {
+		return oneof.Recv4([4]<-chan int(chans), cancel)
+	}},
+	{8, func(chans []<-chan int, cancel <-chan struct{}) (int, int, bool, bool) {
+		return oneof.Recv8([8]<-chan int(chans), cancel)
+	}},
+	{16, func(chans []<-chan int, cancel <-chan struct{}) (int, int, bool, bool) {
+		return oneof.Recv16([16]<-chan int(chans), cancel)
+	}},
+	{32, func(chans []<-chan int, cancel <-chan struct{}) (int, int, bool, bool) {
+		return oneof.Recv32([32]<-chan int(chans), cancel)
+	}},
+	{64, func(chans []<-chan int, cancel <-chan struct{}) (int, int, bool, bool) {
+		return oneof.Recv64([64]<-chan int(chans), cancel)
+	}},
+}
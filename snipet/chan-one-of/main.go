@@ -2,9 +2,15 @@ package main
 
 import (
 	"chan-one-of/oneof"
+	"chan-one-of/oneof/observer"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"math/rand/v2"
+	"os"
+	"slices"
+	"time"
 )
 
 func main() {
@@ -24,12 +30,8 @@ func main() {
 		received := map[int]bool{}
 		done := make(chan struct{})
 		go func() {
-			for {
-				v, chosen, ok := oneof.Recv(receiver, ctx.Done())
-				fmt.Printf("Recv: value = %d, chosen = %d, received = %t\n", v, chosen, ok)
-				if !ok {
-					break
-				}
+			for v, chosen := range oneof.All(receiver, ctx) {
+				fmt.Printf("Recv: value = %d, chosen = %d\n", v, chosen)
 				received[chosen] = true
 			}
 			close(done)
@@ -46,6 +48,171 @@ func main() {
 		}
 		fmt.Println()
 	}
+
+	ctx, cancel := context.WithTimeoutCause(context.Background(), time.Millisecond, errors.New("nobody is listening"))
+	defer cancel()
+	targets := make([]chan int, 5)
+	for i := range targets {
+		targets[i] = make(chan int)
+	}
+	sent, err := oneof.SendEachCtx(sendOnly(targets), func() int { return rand.N(32) }, ctx)
+	fmt.Printf("SendEachCtx: sent = %#v, err = %v\n", sent, err)
+	// SendEachCtx: sent = []int{}, err = nobody is listening
+
+	for i, t := range targets {
+		go func() {
+			t <- i
+			close(t)
+		}()
+	}
+	var values []int
+	closed, completed := oneof.RecvEach(recvOnly(targets), func(v int, _ int) { values = append(values, v) }, nil)
+	slices.Sort(values)
+	slices.Sort(closed)
+	fmt.Printf("RecvEach: values = %#v, closed = %#v, completed = %t\n", values, closed, completed)
+	// RecvEach: values = []int{0, 1, 2, 3, 4}, closed = []int{0, 1, 2, 3, 4}, completed = true
+
+	first, second := make(chan int), make(chan int)
+	set := oneof.NewSet[int](first)
+	go func() {
+		first <- 1
+		first <- 2
+		close(first)
+	}()
+	for v, id := range set.All(context.Background()) {
+		fmt.Printf("Set.All: value = %d, id = %d\n", v, id)
+		if v == 1 {
+			set.Add(second)
+			go func() {
+				second <- 10
+				close(second)
+			}()
+		}
+	}
+	fmt.Printf("Set.Len = %d\n", set.Len())
+	/*
+		Set.All: value = 1, id = 0
+		Set.All: value = 2, id = 0 // this and the next line may be swapped.
+		Set.All: value = 10, id = 1
+		Set.Len = 0
+	*/
+
+	subscribers := make([]chan string, 3)
+	for i := range subscribers {
+		subscribers[i] = make(chan string, 1)
+	}
+	subscribers[1] <- "not yet consumed"
+	results := oneof.Broadcast(sendOnly(subscribers), "notified", context.Background(), time.Millisecond)
+	fmt.Printf("Broadcast: %v\n", results)
+	// Broadcast: [delivered timed out delivered]
+
+	control, data := make(chan int, 1), make(chan int, 1)
+	refill := func() {
+		for _, ch := range []chan int{control, data} {
+			select {
+			case ch <- 0:
+			default:
+			}
+		}
+	}
+	var byTier [2]int
+	for range 1000 {
+		refill()
+		_, tier, _, _, _ := oneof.RecvPriority([][]<-chan int{{control}, {data}}, nil)
+		byTier[tier]++
+	}
+	fmt.Printf("RecvPriority: control = %d, data = %d\n", byTier[0], byTier[1])
+	// RecvPriority: control = 1000, data = 0
+
+	weighted := oneof.NewWeighted([]<-chan int{control, data}, []int{9, 1})
+	var byChan [2]int
+	for range 1000 {
+		refill()
+		_, chosen, _, _ := weighted.Recv(nil)
+		byChan[chosen]++
+	}
+	fmt.Printf("Weighted: control = %d, data = %d\n", byChan[0], byChan[1])
+	// Weighted: control = 900, data = 100
+
+	producers := make([]chan int, 3)
+	for i := range producers {
+		producers[i] = make(chan int)
+		go func() {
+			for j := range 3 {
+				producers[i] <- i*10 + j
+			}
+			close(producers[i])
+		}()
+	}
+	batchChans := recvOnly(producers)
+	for remaining := len(batchChans); remaining > 0; {
+		batch, closed, err := oneof.RecvBatch(batchChans, 4, 10*time.Millisecond, context.Background())
+		fmt.Printf("RecvBatch: len(batch) = %d, closed = %d, err = %v\n", len(batch), len(closed), err)
+		for _, i := range closed {
+			batchChans[i] = nil
+		}
+		remaining -= len(closed)
+	}
+	/*
+		closed varies by scheduling.
+
+		RecvBatch: len(batch) = 4, closed = 1, err = <nil>
+		RecvBatch: len(batch) = 4, closed = 1, err = <nil>
+		RecvBatch: len(batch) = 1, closed = 1, err = <nil>
+	*/
+
+	jobs, outputs := make(chan int), make(chan string)
+	for range 2 {
+		go func() {
+			for j := range jobs {
+				outputs <- fmt.Sprintf("job %d done", j)
+			}
+		}()
+	}
+	var (
+		pending = []int{1, 2, 3}
+		done    int
+		sel     = oneof.NewSelector()
+	)
+	for done < 3 {
+		sel.Reset()
+		if len(pending) > 0 {
+			oneof.AddSend(sel, jobs, pending[0], func() { pending = pending[1:] })
+		}
+		oneof.AddRecv(sel, outputs, func(r string, _ bool) {
+			done++
+			fmt.Printf("Selector: %s\n", r)
+		})
+		sel.Select(nil)
+	}
+	close(jobs)
+	/*
+		order may vary.
+
+		Selector: job 1 done
+		Selector: job 2 done
+		Selector: job 3 done
+	*/
+
+	counter := observer.NewExpvar("oneof")
+	buffered := make([]chan int, 3)
+	for i := range buffered {
+		buffered[i] = make(chan int, 1)
+	}
+	_, _ = oneof.SendEachWith(sendOnly(buffered), func() int { return 0 }, nil, oneof.Options{Observer: counter})
+	fmt.Printf("Expvar: send.count = %s, send.chosen.1 = %s\n", counter.Map().Get("send.count"), counter.Map().Get("send.chosen.1"))
+	// Expvar: send.count = 3, send.chosen.1 = 1
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "wait" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	_, _, _, _ = oneof.RecvWith(recvOnly(buffered), nil, oneof.Options{Observer: &observer.Slog{Logger: logger, Level: slog.LevelInfo}})
+	// level=INFO msg="oneof select" op=recv len=3 chosen=2 cancelled=false
 }
 
 func sendOnly[T ~[]C, C ~(chan E), E any](s T) []chan<- E {
@@ -0,0 +1,81 @@
+package oneof
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+type Received[E any] struct {
+	Value  E
+	Chosen int
+}
+
+// RecvBatch receives up to limit values from chans.
+// It blocks until the first value arrives,
+// then keeps receiving until it has limit values or linger has elapsed since the first one.
+// linger <= 0 means only values already ready are taken after the first one.
+//
+// Channels found closed are dropped for the rest of the call and reported in closed.
+// nil channels are ignored, so callers looping over RecvBatch should set chans[i] to nil
+// for every i reported in closed, as SendEach does with sent channels.
+// RecvBatch returns early once all channels are closed or nil.
+// If ctx is done, values received so far are returned along with context.Cause(ctx).
+func RecvBatch[T ~[]C, C ~(<-chan E), E any](chans T, limit int, linger time.Duration, ctx context.Context) (batch []Received[E], closed []int, err error) {
+	return RecvBatchWith(chans, limit, linger, ctx, Options{})
+}
+
+// RecvBatchWith is like RecvBatch but receives by RecvCtxWith with opts.
+// Non-blocking receives after the first value are observed too, as cancelled if nothing was ready.
+func RecvBatchWith[T ~[]C, C ~(<-chan E), E any](chans T, limit int, linger time.Duration, ctx context.Context, opts Options) (batch []Received[E], closed []int, err error) {
+	if limit <= 0 {
+		panic("limit must be positive")
+	}
+	chans = slices.Clone(chans)
+	var remaining int
+	for _, ch := range chans {
+		if ch != nil {
+			remaining++
+		}
+	}
+	recvCtx := ctx
+
+	for len(batch) < limit && remaining > 0 {
+		var (
+			v      E
+			chosen int
+			ok     bool
+		)
+		if len(batch) > 0 && linger <= 0 {
+			var received bool
+			start := opts.now()
+			v, chosen, ok, received = TryRecv(chans)
+			opts.observe(OpRecv, len(chans), start, chosen, received)
+			if !received {
+				break
+			}
+		} else {
+			v, chosen, ok, err = RecvCtxWith(chans, recvCtx, opts)
+			if err != nil {
+				if ctx.Err() != nil {
+					return batch, closed, context.Cause(ctx)
+				}
+				// lingered enough.
+				break
+			}
+		}
+		if !ok {
+			closed = append(closed, chosen)
+			chans[chosen] = nil
+			remaining--
+			continue
+		}
+		batch = append(batch, Received[E]{Value: v, Chosen: chosen})
+		if len(batch) == 1 && linger > 0 {
+			var cancel context.CancelFunc
+			recvCtx, cancel = context.WithTimeout(ctx, linger)
+			defer cancel()
+		}
+	}
+	return batch, closed, nil
+}
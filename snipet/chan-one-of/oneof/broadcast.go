@@ -0,0 +1,75 @@
+package oneof
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type BroadcastResult int
+
+const (
+	BroadcastDelivered BroadcastResult = iota + 1
+	BroadcastTimedOut
+	BroadcastCancelled
+)
+
+func (r BroadcastResult) String() string {
+	switch r {
+	case BroadcastDelivered:
+		return "delivered"
+	case BroadcastTimedOut:
+		return "timed out"
+	case BroadcastCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+var errReceiverTimedOut = errors.New("receiver timed out")
+
+// Broadcast sends v to every channel in chans and reports the result for each index.
+//
+// All sends are pending at once and v goes to receivers in the order they become ready,
+// so a slow receiver never delays others.
+// Sends not completed within perReceiverTimeout are given up as BroadcastTimedOut,
+// or as BroadcastCancelled if ctx is done before that.
+// perReceiverTimeout <= 0 means no timeout.
+func Broadcast[T ~[]C, C ~(chan<- E), E any](chans T, v E, ctx context.Context, perReceiverTimeout time.Duration) []BroadcastResult {
+	return BroadcastWith(chans, v, ctx, perReceiverTimeout, Options{})
+}
+
+// BroadcastWith is like Broadcast but sends by SendEachCtxWith with opts.
+func BroadcastWith[T ~[]C, C ~(chan<- E), E any](chans T, v E, ctx context.Context, perReceiverTimeout time.Duration, opts Options) []BroadcastResult {
+	results := make([]BroadcastResult, len(chans))
+	if len(chans) == 0 {
+		return results
+	}
+
+	sendCtx := ctx
+	if perReceiverTimeout > 0 {
+		var cancel context.CancelFunc
+		sendCtx, cancel = context.WithTimeoutCause(ctx, perReceiverTimeout, errReceiverTimedOut)
+		defer cancel()
+	}
+
+	sent, err := SendEachCtxWith(chans, func() E { return v }, sendCtx, opts)
+	for _, i := range sent {
+		results[i] = BroadcastDelivered
+	}
+	if err == nil {
+		return results
+	}
+
+	rest := BroadcastCancelled
+	if errors.Is(err, errReceiverTimedOut) {
+		rest = BroadcastTimedOut
+	}
+	for i, r := range results {
+		if r == 0 {
+			results[i] = rest
+		}
+	}
+	return results
+}
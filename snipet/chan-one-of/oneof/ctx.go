@@ -0,0 +1,109 @@
+package oneof
+
+import (
+	"context"
+	"slices"
+)
+
+// SendEachCtx is like SendEach but reports the cause of ctx cancellation.
+// err is nil if fn's values have been sent to every channel in chans,
+// otherwise err is context.Cause(ctx).
+func SendEachCtx[T ~[]C, C ~(chan<- E), E any](chans T, fn func() E, ctx context.Context) (sent []int, err error) {
+	return SendEachCtxWith(chans, fn, ctx, Options{})
+}
+
+// SendEachCtxWith is like SendEachCtx but sends by SendCtxWith with opts.
+func SendEachCtxWith[T ~[]C, C ~(chan<- E), E any](chans T, fn func() E, ctx context.Context, opts Options) (sent []int, err error) {
+	chans = slices.Clone(chans)
+	sent = make([]int, 0, len(chans))
+
+	for len(chans) != len(sent) {
+		chosen, err := SendCtxWith(chans, fn(), ctx, opts)
+		if err != nil {
+			return sent, err
+		}
+		sent = append(sent, chosen)
+		chans[chosen] = nil
+	}
+	return sent, nil
+}
+
+// SendCtx is like Send but takes ctx instead of a cancel channel.
+// Deadlines are observed through ctx.Done, so no timer is allocated per call.
+// On cancellation chosen is zero and err is context.Cause(ctx).
+//
+// SendCtx never sends if ctx is already done when it is called.
+func SendCtx[T ~[]C, C ~(chan<- E), E any](chans T, v E, ctx context.Context) (chosen int, err error) {
+	return SendCtxWith(chans, v, ctx, Options{})
+}
+
+// SendCtxWith is like SendCtx but sends by SendWith with opts.
+// A call returning early since ctx is already done is observed as cancelled.
+func SendCtxWith[T ~[]C, C ~(chan<- E), E any](chans T, v E, ctx context.Context, opts Options) (chosen int, err error) {
+	if ctx.Err() != nil {
+		opts.observe(OpSend, len(chans), opts.now(), 0, false)
+		return 0, context.Cause(ctx)
+	}
+	chosen, sent := SendWith(chans, v, ctx.Done(), opts)
+	if !sent {
+		return 0, context.Cause(ctx)
+	}
+	return chosen, nil
+}
+
+// RecvCtx is like Recv but takes ctx instead of a cancel channel.
+// Deadlines are observed through ctx.Done, so no timer is allocated per call.
+// On cancellation chosen is zero and err is context.Cause(ctx).
+//
+// RecvCtx never receives if ctx is already done when it is called.
+func RecvCtx[T ~[]C, C ~(<-chan E), E any](chans T, ctx context.Context) (v E, chosen int, ok bool, err error) {
+	return RecvCtxWith(chans, ctx, Options{})
+}
+
+// RecvCtxWith is like RecvCtx but receives by RecvWith with opts.
+// A call returning early since ctx is already done is observed as cancelled.
+func RecvCtxWith[T ~[]C, C ~(<-chan E), E any](chans T, ctx context.Context, opts Options) (v E, chosen int, ok bool, err error) {
+	if ctx.Err() != nil {
+		opts.observe(OpRecv, len(chans), opts.now(), 0, false)
+		return v, 0, false, context.Cause(ctx)
+	}
+	v, chosen, ok, received := RecvWith(chans, ctx.Done(), opts)
+	if !received {
+		return v, 0, false, context.Cause(ctx)
+	}
+	return v, chosen, ok, nil
+}
+
+// RecvEachCtx is like RecvEach but reports the cause of ctx cancellation.
+// err is nil if every channel in chans has been closed,
+// otherwise err is context.Cause(ctx).
+func RecvEachCtx[T ~[]C, C ~(<-chan E), E any](chans T, fn func(v E, chosen int), ctx context.Context) (closed []int, err error) {
+	return RecvEachCtxWith(chans, fn, ctx, Options{})
+}
+
+// RecvEachCtxWith is like RecvEachCtx but selects over chans by the strategy of opts, as RecvEachWith does.
+func RecvEachCtxWith[T ~[]C, C ~(<-chan E), E any](chans T, fn func(v E, chosen int), ctx context.Context, opts Options) (closed []int, err error) {
+	if opts.strategy(len(chans), true) == StrategyFanIn {
+		closed, completed := recvEachFanIn(chans, fn, ctx.Done(), opts)
+		if !completed {
+			return closed, context.Cause(ctx)
+		}
+		return closed, nil
+	}
+	chans = slices.Clone(chans)
+	closed = make([]int, 0, len(chans))
+
+	for len(chans) != len(closed) {
+		v, chosen, ok, err := RecvCtxWith(chans, ctx, opts)
+		if err != nil {
+			return closed, err
+		}
+		if !ok {
+			closed = append(closed, chosen)
+			chans[chosen] = nil
+			continue
+		}
+		fn(v, chosen)
+	}
+	return closed, nil
+}
@@ -0,0 +1,67 @@
+package oneof
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type recorder []Event
+
+func (r *recorder) Observe(e Event) {
+	*r = append(*r, e)
+}
+
+func TestCtxWithObserves(t *testing.T) {
+	chans := []chan int{make(chan int, 1), make(chan int, 1)}
+	send := []chan<- int{chans[0], chans[1]}
+	recv := []<-chan int{chans[0], chans[1]}
+
+	var r recorder
+	opts := Options{Observer: &r}
+	sent, err := SendEachCtxWith(send, func() int { return 1 }, context.Background(), opts)
+	if err != nil || len(sent) != 2 {
+		t.Fatalf("sent = %v, err = %v", sent, err)
+	}
+	if _, _, _, err := RecvCtxWith(recv, context.Background(), opts); err != nil {
+		t.Fatal(err)
+	}
+	if len(r) != 3 || r[0].Op != OpSend || r[1].Op != OpSend || r[2].Op != OpRecv || r[2].Cancelled {
+		t.Fatalf("events = %+v", r)
+	}
+
+	r = nil
+	cause := errors.New("cause")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(cause)
+	if _, err := SendCtxWith(send, 1, ctx, opts); err != cause {
+		t.Fatalf("err = %v", err)
+	}
+	if len(r) != 1 || !r[0].Cancelled || r[0].Chosen != -1 || r[0].Len != 2 {
+		t.Fatalf("events = %+v", r)
+	}
+}
+
+func TestBatchAndBroadcastWithObserve(t *testing.T) {
+	chans := []chan int{make(chan int, 2), make(chan int, 2)}
+	send := []chan<- int{chans[0], chans[1]}
+	recv := []<-chan int{chans[0], chans[1]}
+
+	var r recorder
+	opts := Options{Observer: &r}
+	results := BroadcastWith(send, 1, context.Background(), time.Second, opts)
+	if len(results) != 2 || results[0] != BroadcastDelivered || results[1] != BroadcastDelivered || len(r) != 2 {
+		t.Fatalf("results = %v, events = %+v", results, r)
+	}
+
+	r = nil
+	batch, _, err := RecvBatchWith(recv, 4, 0, context.Background(), opts)
+	if err != nil || len(batch) != 2 {
+		t.Fatalf("batch = %v, err = %v", batch, err)
+	}
+	// a blocking receive, a non-blocking one, and one finding nothing ready.
+	if len(r) != 3 || r[0].Cancelled || r[1].Cancelled || !r[2].Cancelled {
+		t.Fatalf("events = %+v", r)
+	}
+}
@@ -0,0 +1,3 @@
+package oneof
+
+//go:generate go run ./internal/genselect -arity 4,8,16,32,64 -o select_gen.go -bench ../cmd/bench/select_gen.go
@@ -0,0 +1,208 @@
+// genselect generates fixed-arity select functions of the oneof package,
+// and benchmarks comparing them to reflect.Select.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+var (
+	arity     = flag.String("arity", "4,8,16", "comma separated list of arities to generate")
+	out       = flag.String("o", "select_gen.go", "output file for select functions")
+	benchOut  = flag.String("bench", "", "output file for benchmarks. skipped if empty")
+	importPkg = flag.String("import", "chan-one-of/oneof", "import path of the package, used by benchmarks")
+)
+
+type data struct {
+	Command string
+	Import  string
+	Arities []int
+	Max     int
+}
+
+func main() {
+	flag.Parse()
+
+	arities, err := parseArity(*arity)
+	if err != nil {
+		panic(err)
+	}
+
+	d := data{
+		Command: "genselect " + strings.Join(os.Args[1:], " "),
+		Import:  *importPkg,
+		Arities: arities,
+		Max:     arities[len(arities)-1],
+	}
+
+	if err := execute(selectTmpl, d, *out); err != nil {
+		panic(err)
+	}
+	if *benchOut != "" {
+		if err := execute(benchTmpl, d, *benchOut); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func parseArity(s string) ([]int, error) {
+	var arities []int
+	for _, a := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(a))
+		if err != nil {
+			return nil, fmt.Errorf("parsing arity %q: %w", a, err)
+		}
+		if n <= 0 {
+			return nil, fmt.Errorf("arity must be positive but is %d", n)
+		}
+		arities = append(arities, n)
+	}
+	slices.Sort(arities)
+	arities = slices.Compact(arities)
+	if len(arities) == 0 {
+		return nil, fmt.Errorf("no arity")
+	}
+	return arities, nil
+}
+
+func execute(tmpl *template.Template, d data, path string) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, d); err != nil {
+		return err
+	}
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %w\n%s", err, buf.String())
+	}
+	return os.WriteFile(path, formatted, 0o644)
+}
+
+var funcs = template.FuncMap{
+	"seq": func(n int) []int {
+		s := make([]int, n)
+		for i := range s {
+			s[i] = i
+		}
+		return s
+	},
+}
+
+var selectTmpl = template.Must(template.New("select").Funcs(funcs).Parse(`// Code generated by {{.Command}}. DO NOT EDIT.
+
+package oneof
+
+// MaxFixedArity is the largest number of channels Send and Recv handle without reflect.Select.
+const MaxFixedArity = {{.Max}}
+
+func sendFixed[T ~[]C, C ~(chan<- E), E any](chans T, v E, cancel <-chan struct{}) (chosen int, sent, handled bool) {
+	switch x := len(chans); {
+{{- range .Arities}}
+	case x <= {{.}}:
+		var c [{{.}}]C
+		_ = copy(c[:], chans)
+		chosen, sent = Send{{.}}(c, v, cancel)
+		return chosen, sent, true
+{{- end}}
+	}
+	return 0, false, false
+}
+
+func recvFixed[T ~[]C, C ~(<-chan E), E any](chans T, cancel <-chan struct{}) (v E, chosen int, ok, received, handled bool) {
+	switch x := len(chans); {
+{{- range .Arities}}
+	case x <= {{.}}:
+		var c [{{.}}]C
+		_ = copy(c[:], chans)
+		v, chosen, ok, received = Recv{{.}}(c, cancel)
+		return v, chosen, ok, received, true
+{{- end}}
+	}
+	return v, 0, false, false, false
+}
+
+func tryRecvFixed[T ~[]C, C ~(<-chan E), E any](chans T) (v E, chosen int, ok, received, handled bool) {
+	switch x := len(chans); {
+{{- range .Arities}}
+	case x <= {{.}}:
+		var c [{{.}}]C
+		_ = copy(c[:], chans)
+		v, chosen, ok, received = TryRecv{{.}}(c)
+		return v, chosen, ok, received, true
+{{- end}}
+	}
+	return v, 0, false, false, false
+}
+{{range .Arities}}
+func Send{{.}}[T ~[{{.}}]C, C ~(chan<- E), E any](chans T, v E, cancel <-chan struct{}) (chosen int, sent bool) {
+	sent = true
+	select {
+	case <-cancel:
+		sent = false
+{{- range seq .}}
+	case chans[{{.}}] <- v:
+		chosen = {{.}}
+{{- end}}
+	}
+	return
+}
+{{end}}
+{{- range .Arities}}
+func Recv{{.}}[T ~[{{.}}]C, C ~(<-chan E), E any](chans T, cancel <-chan struct{}) (v E, chosen int, ok, received bool) {
+	received = true
+	select {
+	case <-cancel:
+		// chosen should stay zero, to prevent misuse.
+		received = false
+{{- range seq .}}
+	case v, ok = <-chans[{{.}}]:
+		chosen = {{.}}
+{{- end}}
+	}
+	return
+}
+{{end}}
+{{- range .Arities}}
+func TryRecv{{.}}[T ~[{{.}}]C, C ~(<-chan E), E any](chans T) (v E, chosen int, ok, received bool) {
+	received = true
+	select {
+	default:
+		received = false
+{{- range seq .}}
+	case v, ok = <-chans[{{.}}]:
+		chosen = {{.}}
+{{- end}}
+	}
+	return
+}
+{{end}}`))
+
+var benchTmpl = template.Must(template.New("bench").Funcs(funcs).Parse(`// Code generated by {{.Command}}. DO NOT EDIT.
+
+package main
+
+import "{{.Import}}"
+
+var fixedSend = []fixedSendFunc{
+{{- range .Arities}}
+	{ {{- .}}, func(chans []chan<- int, v int, cancel <-chan struct{}) (int, bool) {
+		return oneof.Send{{.}}([{{.}}]chan<- int(chans), v, cancel)
+	}},
+{{- end}}
+}
+
+var fixedRecv = []fixedRecvFunc{
+{{- range .Arities}}
+	{ {{- .}}, func(chans []<-chan int, cancel <-chan struct{}) (int, int, bool, bool) {
+		return oneof.Recv{{.}}([{{.}}]<-chan int(chans), cancel)
+	}},
+{{- end}}
+}
+`))
@@ -0,0 +1,144 @@
+package oneof
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"sync"
+)
+
+// All returns an iterator over values received from chans, paired with the index of the channel.
+// Closed channels are dropped from the set.
+// The iterator stops once every channel is closed or ctx is done.
+func All[T ~[]C, C ~(<-chan E), E any](chans T, ctx context.Context) iter.Seq2[E, int] {
+	return func(yield func(E, int) bool) {
+		chans := slices.Clone(chans)
+		for remaining := len(chans); remaining > 0; {
+			v, chosen, ok, err := RecvCtx(chans, ctx)
+			if err != nil {
+				return
+			}
+			if !ok {
+				chans[chosen] = nil
+				remaining--
+				continue
+			}
+			if !yield(v, chosen) {
+				return
+			}
+		}
+	}
+}
+
+// Set is a set of channels which can be changed while being iterated over by All.
+// Each channel is identified by an id returned from Add.
+//
+// The zero Set is empty and ready to use.
+type Set[E any] struct {
+	mu     sync.Mutex
+	nextId int
+	ids    []int
+	chans  []<-chan E
+	// changed is closed when the set is changed, to wake blocked iterators.
+	changed chan E
+}
+
+func NewSet[E any](chans ...<-chan E) *Set[E] {
+	s := &Set[E]{}
+	for _, ch := range chans {
+		s.Add(ch)
+	}
+	return s
+}
+
+func (s *Set[E]) Add(ch <-chan E) (id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id = s.nextId
+	s.nextId++
+	s.ids = append(s.ids, id)
+	s.chans = append(s.chans, ch)
+	s.notify()
+	return id
+}
+
+func (s *Set[E]) Remove(id int) (removed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.Index(s.ids, id)
+	if i < 0 {
+		return false
+	}
+	s.ids = slices.Delete(s.ids, i, i+1)
+	s.chans = slices.Delete(s.chans, i, i+1)
+	s.notify()
+	return true
+}
+
+func (s *Set[E]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.chans)
+}
+
+func (s *Set[E]) notify() {
+	if s.changed != nil {
+		close(s.changed)
+		s.changed = nil
+	}
+}
+
+// snapshot returns ids and chans of the set.
+// The last element of chans is closed when the set is changed afterwards.
+func (s *Set[E]) snapshot() (ids []int, chans []<-chan E) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.changed == nil {
+		s.changed = make(chan E)
+	}
+	return slices.Clone(s.ids), append(slices.Clone(s.chans), s.changed)
+}
+
+// changed reports whether the set has been changed since ch was returned from snapshot.
+// Checked before each receive, since a select over a stale snapshot
+// could pick a removed channel over the closed ch at random.
+func changed[E any](ch <-chan E) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// All returns an iterator over values received from channels in s, paired with id of the channel.
+// Channels added to or removed from s, even by the loop body, are reflected without stopping the iteration:
+// no value is received from a channel once Remove for it has returned, unless the receive was already blocking.
+// Closed channels are removed from s.
+// The iterator stops once s becomes empty or ctx is done.
+func (s *Set[E]) All(ctx context.Context) iter.Seq2[E, int] {
+	return func(yield func(E, int) bool) {
+		for {
+			ids, chans := s.snapshot()
+			if len(ids) == 0 {
+				return
+			}
+			for !changed(chans[len(ids)]) {
+				v, chosen, ok, err := RecvCtx(chans, ctx)
+				if err != nil {
+					return
+				}
+				if chosen == len(ids) {
+					break
+				}
+				if !ok {
+					s.Remove(ids[chosen])
+					break
+				}
+				if !yield(v, ids[chosen]) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package oneof
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetAllRemoveInLoopBody(t *testing.T) {
+	for range 1000 {
+		a, b := make(chan int, 8), make(chan int, 8)
+		for i := range 8 {
+			a <- i
+			b <- i
+		}
+		close(a)
+		close(b)
+
+		s := NewSet[int](a, b)
+		removed := -1
+		for _, id := range s.All(context.Background()) {
+			if removed < 0 {
+				removed = 1 - id
+				s.Remove(removed)
+				continue
+			}
+			if id == removed {
+				t.Fatalf("received from removed channel %d", id)
+			}
+		}
+	}
+}
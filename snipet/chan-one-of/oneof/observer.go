@@ -0,0 +1,62 @@
+package oneof
+
+import "time"
+
+type Op int
+
+const (
+	OpSend Op = iota + 1
+	OpRecv
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpSend:
+		return "send"
+	case OpRecv:
+		return "recv"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes a single select made by SendWith, RecvWith or functions built on them.
+type Event struct {
+	Op Op
+	// Len is the number of channels selected over.
+	Len int
+	// Chosen is the index of the channel won. It is -1 if cancelled.
+	Chosen    int
+	Wait      time.Duration
+	Cancelled bool
+}
+
+// Observer is notified of every select made with Options whose Observer is set.
+// Observe is called synchronously after the select, so it should return quickly.
+type Observer interface {
+	Observe(e Event)
+}
+
+func (o Options) observe(op Op, n int, start time.Time, chosen int, done bool) {
+	if o.Observer == nil {
+		return
+	}
+	e := Event{
+		Op:     op,
+		Len:    n,
+		Chosen: chosen,
+		Wait:   time.Since(start),
+	}
+	if !done {
+		e.Chosen = -1
+		e.Cancelled = true
+	}
+	o.Observer.Observe(e)
+}
+
+func (o Options) now() time.Time {
+	if o.Observer == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
@@ -0,0 +1,69 @@
+// Package observer adapts oneof.Observer to log/slog and expvar.
+package observer
+
+import (
+	"chan-one-of/oneof"
+	"context"
+	"expvar"
+	"log/slog"
+	"strconv"
+)
+
+var _ oneof.Observer = (*Slog)(nil)
+
+// Slog logs every event as a slog record.
+type Slog struct {
+	Logger *slog.Logger
+	Level  slog.Level
+}
+
+func (o *Slog) Observe(e oneof.Event) {
+	ctx := context.Background()
+	if !o.Logger.Enabled(ctx, o.Level) {
+		return
+	}
+	o.Logger.LogAttrs(
+		ctx,
+		o.Level,
+		"oneof select",
+		slog.String("op", e.Op.String()),
+		slog.Int("len", e.Len),
+		slog.Int("chosen", e.Chosen),
+		slog.Duration("wait", e.Wait),
+		slog.Bool("cancelled", e.Cancelled),
+	)
+}
+
+var _ oneof.Observer = (*Expvar)(nil)
+
+// Expvar counts events into an expvar.Map.
+//
+// For each op, the map has
+//   - "<op>.count": number of selects
+//   - "<op>.cancelled": number of cancelled selects
+//   - "<op>.wait_ns": sum of wait durations in nanoseconds
+//   - "<op>.chosen.<index>": number of times the index won, to detect skewed consumers.
+type Expvar struct {
+	m *expvar.Map
+}
+
+// NewExpvar publishes a new expvar.Map under name.
+// Like expvar.NewMap, it panics if name is already registered.
+func NewExpvar(name string) *Expvar {
+	return &Expvar{m: expvar.NewMap(name)}
+}
+
+func (o *Expvar) Map() *expvar.Map {
+	return o.m
+}
+
+func (o *Expvar) Observe(e oneof.Event) {
+	op := e.Op.String()
+	o.m.Add(op+".count", 1)
+	o.m.Add(op+".wait_ns", int64(e.Wait))
+	if e.Cancelled {
+		o.m.Add(op+".cancelled", 1)
+		return
+	}
+	o.m.Add(op+".chosen."+strconv.Itoa(e.Chosen), 1)
+}
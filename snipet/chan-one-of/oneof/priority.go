@@ -0,0 +1,91 @@
+package oneof
+
+// RecvPriority receives from tiers in strict priority order:
+// a ready channel in tiers[i] always wins over ones in tiers[j] for i < j.
+// If no channel is ready, it blocks on all tiers at once and
+// the first channel becoming ready wins regardless of its tier.
+func RecvPriority[T ~[]S, S ~[]C, C ~(<-chan E), E any](tiers T, cancel <-chan struct{}) (v E, tier, chosen int, ok, received bool) {
+	var all []C
+	for i, chans := range tiers {
+		if len(chans) == 0 {
+			continue
+		}
+		v, chosen, ok, received = TryRecv(chans)
+		if received {
+			return v, i, chosen, ok, true
+		}
+		all = append(all, chans...)
+	}
+
+	v, chosen, ok, received = Recv(all, cancel)
+	if !received {
+		return v, 0, 0, false, false
+	}
+	for i, chans := range tiers {
+		if chosen < len(chans) {
+			return v, i, chosen, ok, true
+		}
+		chosen -= len(chans)
+	}
+	panic("unreachable")
+}
+
+// Weighted receives from a set of channels in weighted-fair manner.
+//
+// While channels are ready, each is chosen in proportion to its weight,
+// interleaved by smooth weighted round-robin.
+// Every channel with a positive weight is preferred at least once per sum-of-weights receives,
+// so a heavy channel can never starve a light one.
+type Weighted[E any] struct {
+	chans   []<-chan E
+	weights []int
+	current []int
+	total   int
+}
+
+func NewWeighted[E any](chans []<-chan E, weights []int) *Weighted[E] {
+	if len(chans) == 0 {
+		panic("zero chans")
+	}
+	if len(chans) != len(weights) {
+		panic("mismatching len(chans) and len(weights)")
+	}
+	var total int
+	for _, w := range weights {
+		if w <= 0 {
+			panic("weight must be positive")
+		}
+		total += w
+	}
+	return &Weighted[E]{
+		chans:   chans,
+		weights: weights,
+		current: make([]int, len(chans)),
+		total:   total,
+	}
+}
+
+// Recv tries the channel preferred by the weights first.
+// If it is not ready, Recv falls back to receive from any of channels like Recv does.
+// Weighted is not safe for concurrent use.
+func (w *Weighted[E]) Recv(cancel <-chan struct{}) (v E, chosen int, ok, received bool) {
+	preferred := w.next()
+	select {
+	case v, ok = <-w.chans[preferred]:
+		return v, preferred, ok, true
+	default:
+	}
+	return Recv(w.chans, cancel)
+}
+
+func (w *Weighted[E]) next() int {
+	best := 0
+	for i, weight := range w.weights {
+		w.current[i] += weight
+		if w.current[i] > w.current[best] {
+			best = i
+		}
+	}
+	w.current[best] -= w.total
+	return best
+}
@@ -0,0 +1,87 @@
+package oneof
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecvPriorityTierOrder(t *testing.T) {
+	control, data := make(chan int, 10), make(chan int, 10)
+	for i := range 10 {
+		control <- i
+		data <- i
+	}
+	tiers := [][]<-chan int{{control}, {data}}
+	for i := range 20 {
+		_, tier, _, ok, received := RecvPriority(tiers, nil)
+		if !ok || !received {
+			t.Fatalf("ok = %t, received = %t", ok, received)
+		}
+		if want := i / 10; tier != want {
+			t.Fatalf("receive %d: tier = %d, want %d", i, tier, want)
+		}
+	}
+
+	// none is ready; a lower tier becoming ready first wins.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		data <- 1
+	}()
+	_, tier, _, _, _ := RecvPriority(tiers, nil)
+	if tier != 1 {
+		t.Fatalf("tier = %d, want 1", tier)
+	}
+
+	cancel := make(chan struct{})
+	close(cancel)
+	if _, _, _, _, received := RecvPriority(tiers, cancel); received {
+		t.Fatal("received after cancel")
+	}
+}
+
+func TestWeightedStarvationFreedom(t *testing.T) {
+	const heavyWeight, lightWeight = 9, 1
+	const sum = heavyWeight + lightWeight
+
+	heavy, light := make(chan int, 1024), make(chan int, 1)
+	w := NewWeighted([]<-chan int{heavy, light}, []int{heavyWeight, lightWeight})
+
+	// heavy is always ready. light becomes ready at varying points
+	// and must be received within sum receives from then on.
+	var readySince int
+	for i := range 1000 {
+		for len(heavy) < cap(heavy) {
+			heavy <- 0
+		}
+		if len(light) == 0 && i%7 == 0 {
+			light <- 1
+			readySince = i
+		}
+		_, chosen, _, _ := w.Recv(nil)
+		if chosen == 1 {
+			continue
+		}
+		if len(light) > 0 && i-readySince >= sum {
+			t.Fatalf("light has been ready for %d receives without being chosen", i-readySince+1)
+		}
+	}
+
+	// both always ready: each gets exactly its share per sum receives.
+	w = NewWeighted([]<-chan int{heavy, light}, []int{heavyWeight, lightWeight})
+	for round := range 100 {
+		counts := make([]int, 2)
+		for range sum {
+			for len(heavy) < cap(heavy) {
+				heavy <- 0
+			}
+			if len(light) == 0 {
+				light <- 1
+			}
+			_, chosen, _, _ := w.Recv(nil)
+			counts[chosen]++
+		}
+		if counts[0] != heavyWeight || counts[1] != lightWeight {
+			t.Fatalf("round %d: counts = %v", round, counts)
+		}
+	}
+}
@@ -0,0 +1,983 @@
+// Code generated by genselect -arity 4,8,16,32,64 -o select_gen.go -bench ../cmd/bench/select_gen.go. DO NOT EDIT.
+
+package oneof
+
+// MaxFixedArity is the largest number of channels Send and Recv handle without reflect.Select.
+const MaxFixedArity = 64
+
+func sendFixed[T ~[]C, C ~(chan<- E), E any](chans T, v E, cancel <-chan struct{}) (chosen int, sent, handled bool) {
+	switch x := len(chans); {
+	case x <= 4:
+		var c [4]C
+		_ = copy(c[:], chans)
+		chosen, sent = Send4(c, v, cancel)
+		return chosen, sent, true
+	case x <= 8:
+		var c [8]C
+		_ = copy(c[:], chans)
+		chosen, sent = Send8(c, v, cancel)
+		return chosen, sent, true
+	case x <= 16:
+		var c [16]C
+		_ = copy(c[:], chans)
+		chosen, sent = Send16(c, v, cancel)
+		return chosen, sent, true
+	case x <= 32:
+		var c [32]C
+		_ = copy(c[:], chans)
+		chosen, sent = Send32(c, v, cancel)
+		return chosen, sent, true
+	case x <= 64:
+		var c [64]C
+		_ = copy(c[:], chans)
+		chosen, sent = Send64(c, v, cancel)
+		return chosen, sent, true
+	}
+	return 0, false, false
+}
+
+func recvFixed[T ~[]C, C ~(<-chan E), E any](chans T, cancel <-chan struct{}) (v E, chosen int, ok, received, handled bool) {
+	switch x := len(chans); {
+	case x <= 4:
+		var c [4]C
+		_ = copy(c[:], chans)
+		v, chosen, ok, received = Recv4(c, cancel)
+		return v, chosen, ok, received, true
+	case x <= 8:
+		var c [8]C
+		_ = copy(c[:], chans)
+		v, chosen, ok, received = Recv8(c, cancel)
+		return v, chosen, ok, received, true
+	case x <= 16:
+		var c [16]C
+		_ = copy(c[:], chans)
+		v, chosen, ok, received = Recv16(c, cancel)
+		return v, chosen, ok, received, true
+	case x <= 32:
+		var c [32]C
+		_ = copy(c[:], chans)
+		v, chosen, ok, received = Recv32(c, cancel)
+		return v, chosen, ok, received, true
+	case x <= 64:
+		var c [64]C
+		_ = copy(c[:], chans)
+		v, chosen, ok, received = Recv64(c, cancel)
+		return v, chosen, ok, received, true
+	}
+	return v, 0, false, false, false
+}
+
+func tryRecvFixed[T ~[]C, C ~(<-chan E), E any](chans T) (v E, chosen int, ok, received, handled bool) {
+	switch x := len(chans); {
+	case x <= 4:
+		var c [4]C
+		_ = copy(c[:], chans)
+		v, chosen, ok, received = TryRecv4(c)
+		return v, chosen, ok, received, true
+	case x <= 8:
+		var c [8]C
+		_ = copy(c[:], chans)
+		v, chosen, ok, received = TryRecv8(c)
+		return v, chosen, ok, received, true
+	case x <= 16:
+		var c [16]C
+		_ = copy(c[:], chans)
+		v, chosen, ok, received = TryRecv16(c)
+		return v, chosen, ok, received, true
+	case x <= 32:
+		var c [32]C
+		_ = copy(c[:], chans)
+		v, chosen, ok, received = TryRecv32(c)
+		return v, chosen, ok, received, true
+	case x <= 64:
+		var c [64]C
+		_ = copy(c[:], chans)
+		v, chosen, ok, received = TryRecv64(c)
+		return v, chosen, ok, received, true
+	}
+	return v, 0, false, false, false
+}
+
+func Send4[T ~[4]C, C ~(chan<- E), E any](chans T, v E, cancel <-chan struct{}) (chosen int, sent bool) {
+	sent = true
+	select {
+	case <-cancel:
+		sent = false
+	case chans[0] <- v:
+		chosen = 0
+	case chans[1] <- v:
+		chosen = 1
+	case chans[2] <- v:
+		chosen = 2
+	case chans[3] <- v:
+		chosen = 3
+	}
+	return
+}
+
+func Send8[T ~[8]C, C ~(chan<- E), E any](chans T, v E, cancel <-chan struct{}) (chosen int, sent bool) {
+	sent = true
+	select {
+	case <-cancel:
+		sent = false
+	case chans[0] <- v:
+		chosen = 0
+	case chans[1] <- v:
+		chosen = 1
+	case chans[2] <- v:
+		chosen = 2
+	case chans[3] <- v:
+		chosen = 3
+	case chans[4] <- v:
+		chosen = 4
+	case chans[5] <- v:
+		chosen = 5
+	case chans[6] <- v:
+		chosen = 6
+	case chans[7] <- v:
+		chosen = 7
+	}
+	return
+}
+
+func Send16[T ~[16]C, C ~(chan<- E), E any](chans T, v E, cancel <-chan struct{}) (chosen int, sent bool) {
+	sent = true
+	select {
+	case <-cancel:
+		sent = false
+	case chans[0] <- v:
+		chosen = 0
+	case chans[1] <- v:
+		chosen = 1
+	case chans[2] <- v:
+		chosen = 2
+	case chans[3] <- v:
+		chosen = 3
+	case chans[4] <- v:
+		chosen = 4
+	case chans[5] <- v:
+		chosen = 5
+	case chans[6] <- v:
+		chosen = 6
+	case chans[7] <- v:
+		chosen = 7
+	case chans[8] <- v:
+		chosen = 8
+	case chans[9] <- v:
+		chosen = 9
+	case chans[10] <- v:
+		chosen = 10
+	case chans[11] <- v:
+		chosen = 11
+	case chans[12] <- v:
+		chosen = 12
+	case chans[13] <- v:
+		chosen = 13
+	case chans[14] <- v:
+		chosen = 14
+	case chans[15] <- v:
+		chosen = 15
+	}
+	return
+}
+
+func Send32[T ~[32]C, C ~(chan<- E), E any](chans T, v E, cancel <-chan struct{}) (chosen int, sent bool) {
+	sent = true
+	select {
+	case <-cancel:
+		sent = false
+	case chans[0] <- v:
+		chosen = 0
+	case chans[1] <- v:
+		chosen = 1
+	case chans[2] <- v:
+		chosen = 2
+	case chans[3] <- v:
+		chosen = 3
+	case chans[4] <- v:
+		chosen = 4
+	case chans[5] <- v:
+		chosen = 5
+	case chans[6] <- v:
+		chosen = 6
+	case chans[7] <- v:
+		chosen = 7
+	case chans[8] <- v:
+		chosen = 8
+	case chans[9] <- v:
+		chosen = 9
+	case chans[10] <- v:
+		chosen = 10
+	case chans[11] <- v:
+		chosen = 11
+	case chans[12] <- v:
+		chosen = 12
+	case chans[13] <- v:
+		chosen = 13
+	case chans[14] <- v:
+		chosen = 14
+	case chans[15] <- v:
+		chosen = 15
+	case chans[16] <- v:
+		chosen = 16
+	case chans[17] <- v:
+		chosen = 17
+	case chans[18] <- v:
+		chosen = 18
+	case chans[19] <- v:
+		chosen = 19
+	case chans[20] <- v:
+		chosen = 20
+	case chans[21] <- v:
+		chosen = 21
+	case chans[22] <- v:
+		chosen = 22
+	case chans[23] <- v:
+		chosen = 23
+	case chans[24] <- v:
+		chosen = 24
+	case chans[25] <- v:
+		chosen = 25
+	case chans[26] <- v:
+		chosen = 26
+	case chans[27] <- v:
+		chosen = 27
+	case chans[28] <- v:
+		chosen = 28
+	case chans[29] <- v:
+		chosen = 29
+	case chans[30] <- v:
+		chosen = 30
+	case chans[31] <- v:
+		chosen = 31
+	}
+	return
+}
+
+func Send64[T ~[64]C, C ~(chan<- E), E any](chans T, v E, cancel <-chan struct{}) (chosen int, sent bool) {
+	sent = true
+	select {
+	case <-cancel:
+		sent = false
+	case chans[0] <- v:
+		chosen = 0
+	case chans[1] <- v:
+		chosen = 1
+	case chans[2] <- v:
+		chosen = 2
+	case chans[3] <- v:
+		chosen = 3
+	case chans[4] <- v:
+		chosen = 4
+	case chans[5] <- v:
+		chosen = 5
+	case chans[6] <- v:
+		chosen = 6
+	case chans[7] <- v:
+		chosen = 7
+	case chans[8] <- v:
+		chosen = 8
+	case chans[9] <- v:
+		chosen = 9
+	case chans[10] <- v:
+		chosen = 10
+	case chans[11] <- v:
+		chosen = 11
+	case chans[12] <- v:
+		chosen = 12
+	case chans[13] <- v:
+		chosen = 13
+	case chans[14] <- v:
+		chosen = 14
+	case chans[15] <- v:
+		chosen = 15
+	case chans[16] <- v:
+		chosen = 16
+	case chans[17] <- v:
+		chosen = 17
+	case chans[18] <- v:
+		chosen = 18
+	case chans[19] <- v:
+		chosen = 19
+	case chans[20] <- v:
+		chosen = 20
+	case chans[21] <- v:
+		chosen = 21
+	case chans[22] <- v:
+		chosen = 22
+	case chans[23] <- v:
+		chosen = 23
+	case chans[24] <- v:
+		chosen = 24
+	case chans[25] <- v:
+		chosen = 25
+	case chans[26] <- v:
+		chosen = 26
+	case chans[27] <- v:
+		chosen = 27
+	case chans[28] <- v:
+		chosen = 28
+	case chans[29] <- v:
+		chosen = 29
+	case chans[30] <- v:
+		chosen = 30
+	case chans[31] <- v:
+		chosen = 31
+	case chans[32] <- v:
+		chosen = 32
+	case chans[33] <- v:
+		chosen = 33
+	case chans[34] <- v:
+		chosen = 34
+	case chans[35] <- v:
+		chosen = 35
+	case chans[36] <- v:
+		chosen = 36
+	case chans[37] <- v:
+		chosen = 37
+	case chans[38] <- v:
+		chosen = 38
+	case chans[39] <- v:
+		chosen = 39
+	case chans[40] <- v:
+		chosen = 40
+	case chans[41] <- v:
+		chosen = 41
+	case chans[42] <- v:
+		chosen = 42
+	case chans[43] <- v:
+		chosen = 43
+	case chans[44] <- v:
+		chosen = 44
+	case chans[45] <- v:
+		chosen = 45
+	case chans[46] <- v:
+		chosen = 46
+	case chans[47] <- v:
+		chosen = 47
+	case chans[48] <- v:
+		chosen = 48
+	case chans[49] <- v:
+		chosen = 49
+	case chans[50] <- v:
+		chosen = 50
+	case chans[51] <- v:
+		chosen = 51
+	case chans[52] <- v:
+		chosen = 52
+	case chans[53] <- v:
+		chosen = 53
+	case chans[54] <- v:
+		chosen = 54
+	case chans[55] <- v:
+		chosen = 55
+	case chans[56] <- v:
+		chosen = 56
+	case chans[57] <- v:
+		chosen = 57
+	case chans[58] <- v:
+		chosen = 58
+	case chans[59] <- v:
+		chosen = 59
+	case chans[60] <- v:
+		chosen = 60
+	case chans[61] <- v:
+		chosen = 61
+	case chans[62] <- v:
+		chosen = 62
+	case chans[63] <- v:
+		chosen = 63
+	}
+	return
+}
+
+func Recv4[T ~[4]C, C ~(<-chan E), E any](chans T, cancel <-chan struct{}) (v E, chosen int, ok, received bool) {
+	received = true
+	select {
+	case <-cancel:
+		// chosen should stay zero, to prevent misuse.
+		received = false
+	case v, ok = <-chans[0]:
+		chosen = 0
+	case v, ok = <-chans[1]:
+		chosen = 1
+	case v, ok = <-chans[2]:
+		chosen = 2
+	case v, ok = <-chans[3]:
+		chosen = 3
+	}
+	return
+}
+
+func Recv8[T ~[8]C, C ~(<-chan E), E any](chans T, cancel <-chan struct{}) (v E, chosen int, ok, received bool) {
+	received = true
+	select {
+	case <-cancel:
+		// chosen should stay zero, to prevent misuse.
+		received = false
+	case v, ok = <-chans[0]:
+		chosen = 0
+	case v, ok = <-chans[1]:
+		chosen = 1
+	case v, ok = <-chans[2]:
+		chosen = 2
+	case v, ok = <-chans[3]:
+		chosen = 3
+	case v, ok = <-chans[4]:
+		chosen = 4
+	case v, ok = <-chans[5]:
+		chosen = 5
+	case v, ok = <-chans[6]:
+		chosen = 6
+	case v, ok = <-chans[7]:
+		chosen = 7
+	}
+	return
+}
+
+func Recv16[T ~[16]C, C ~(<-chan E), E any](chans T, cancel <-chan struct{}) (v E, chosen int, ok, received bool) {
+	received = true
+	select {
+	case <-cancel:
+		// chosen should stay zero, to prevent misuse.
+		received = false
+	case v, ok = <-chans[0]:
+		chosen = 0
+	case v, ok = <-chans[1]:
+		chosen = 1
+	case v, ok = <-chans[2]:
+		chosen = 2
+	case v, ok = <-chans[3]:
+		chosen = 3
+	case v, ok = <-chans[4]:
+		chosen = 4
+	case v, ok = <-chans[5]:
+		chosen = 5
+	case v, ok = <-chans[6]:
+		chosen = 6
+	case v, ok = <-chans[7]:
+		chosen = 7
+	case v, ok = <-chans[8]:
+		chosen = 8
+	case v, ok = <-chans[9]:
+		chosen = 9
+	case v, ok = <-chans[10]:
+		chosen = 10
+	case v, ok = <-chans[11]:
+		chosen = 11
+	case v, ok = <-chans[12]:
+		chosen = 12
+	case v, ok = <-chans[13]:
+		chosen = 13
+	case v, ok = <-chans[14]:
+		chosen = 14
+	case v, ok = <-chans[15]:
+		chosen = 15
+	}
+	return
+}
+
+func Recv32[T ~[32]C, C ~(<-chan E), E any](chans T, cancel <-chan struct{}) (v E, chosen int, ok, received bool) {
+	received = true
+	select {
+	case <-cancel:
+		// chosen should stay zero, to prevent misuse.
+		received = false
+	case v, ok = <-chans[0]:
+		chosen = 0
+	case v, ok = <-chans[1]:
+		chosen = 1
+	case v, ok = <-chans[2]:
+		chosen = 2
+	case v, ok = <-chans[3]:
+		chosen = 3
+	case v, ok = <-chans[4]:
+		chosen = 4
+	case v, ok = <-chans[5]:
+		chosen = 5
+	case v, ok = <-chans[6]:
+		chosen = 6
+	case v, ok = <-chans[7]:
+		chosen = 7
+	case v, ok = <-chans[8]:
+		chosen = 8
+	case v, ok = <-chans[9]:
+		chosen = 9
+	case v, ok = <-chans[10]:
+		chosen = 10
+	case v, ok = <-chans[11]:
+		chosen = 11
+	case v, ok = <-chans[12]:
+		chosen = 12
+	case v, ok = <-chans[13]:
+		chosen = 13
+	case v, ok = <-chans[14]:
+		chosen = 14
+	case v, ok = <-chans[15]:
+		chosen = 15
+	case v, ok = <-chans[16]:
+		chosen = 16
+	case v, ok = <-chans[17]:
+		chosen = 17
+	case v, ok = <-chans[18]:
+		chosen = 18
+	case v, ok = <-chans[19]:
+		chosen = 19
+	case v, ok = <-chans[20]:
+		chosen = 20
+	case v, ok = <-chans[21]:
+		chosen = 21
+	case v, ok = <-chans[22]:
+		chosen = 22
+	case v, ok = <-chans[23]:
+		chosen = 23
+	case v, ok = <-chans[24]:
+		chosen = 24
+	case v, ok = <-chans[25]:
+		chosen = 25
+	case v, ok = <-chans[26]:
+		chosen = 26
+	case v, ok = <-chans[27]:
+		chosen = 27
+	case v, ok = <-chans[28]:
+		chosen = 28
+	case v, ok = <-chans[29]:
+		chosen = 29
+	case v, ok = <-chans[30]:
+		chosen = 30
+	case v, ok = <-chans[31]:
+		chosen = 31
+	}
+	return
+}
+
+func Recv64[T ~[64]C, C ~(<-chan E), E any](chans T, cancel <-chan struct{}) (v E, chosen int, ok, received bool) {
+	received = true
+	select {
+	case <-cancel:
+		// chosen should stay zero, to prevent misuse.
+		received = false
+	case v, ok = <-chans[0]:
+		chosen = 0
+	case v, ok = <-chans[1]:
+		chosen = 1
+	case v, ok = <-chans[2]:
+		chosen = 2
+	case v, ok = <-chans[3]:
+		chosen = 3
+	case v, ok = <-chans[4]:
+		chosen = 4
+	case v, ok = <-chans[5]:
+		chosen = 5
+	case v, ok = <-chans[6]:
+		chosen = 6
+	case v, ok = <-chans[7]:
+		chosen = 7
+	case v, ok = <-chans[8]:
+		chosen = 8
+	case v, ok = <-chans[9]:
+		chosen = 9
+	case v, ok = <-chans[10]:
+		chosen = 10
+	case v, ok = <-chans[11]:
+		chosen = 11
+	case v, ok = <-chans[12]:
+		chosen = 12
+	case v, ok = <-chans[13]:
+		chosen = 13
+	case v, ok = <-chans[14]:
+		chosen = 14
+	case v, ok = <-chans[15]:
+		chosen = 15
+	case v, ok = <-chans[16]:
+		chosen = 16
+	case v, ok = <-chans[17]:
+		chosen = 17
+	case v, ok = <-chans[18]:
+		chosen = 18
+	case v, ok = <-chans[19]:
+		chosen = 19
+	case v, ok = <-chans[20]:
+		chosen = 20
+	case v, ok = <-chans[21]:
+		chosen = 21
+	case v, ok = <-chans[22]:
+		chosen = 22
+	case v, ok = <-chans[23]:
+		chosen = 23
+	case v, ok = <-chans[24]:
+		chosen = 24
+	case v, ok = <-chans[25]:
+		chosen = 25
+	case v, ok = <-chans[26]:
+		chosen = 26
+	case v, ok = <-chans[27]:
+		chosen = 27
+	case v, ok = <-chans[28]:
+		chosen = 28
+	case v, ok = <-chans[29]:
+		chosen = 29
+	case v, ok = <-chans[30]:
+		chosen = 30
+	case v, ok = <-chans[31]:
+		chosen = 31
+	case v, ok = <-chans[32]:
+		chosen = 32
+	case v, ok = <-chans[33]:
+		chosen = 33
+	case v, ok = <-chans[34]:
+		chosen = 34
+	case v, ok = <-chans[35]:
+		chosen = 35
+	case v, ok = <-chans[36]:
+		chosen = 36
+	case v, ok = <-chans[37]:
+		chosen = 37
+	case v, ok = <-chans[38]:
+		chosen = 38
+	case v, ok = <-chans[39]:
+		chosen = 39
+	case v, ok = <-chans[40]:
+		chosen = 40
+	case v, ok = <-chans[41]:
+		chosen = 41
+	case v, ok = <-chans[42]:
+		chosen = 42
+	case v, ok = <-chans[43]:
+		chosen = 43
+	case v, ok = <-chans[44]:
+		chosen = 44
+	case v, ok = <-chans[45]:
+		chosen = 45
+	case v, ok = <-chans[46]:
+		chosen = 46
+	case v, ok = <-chans[47]:
+		chosen = 47
+	case v, ok = <-chans[48]:
+		chosen = 48
+	case v, ok = <-chans[49]:
+		chosen = 49
+	case v, ok = <-chans[50]:
+		chosen = 50
+	case v, ok = <-chans[51]:
+		chosen = 51
+	case v, ok = <-chans[52]:
+		chosen = 52
+	case v, ok = <-chans[53]:
+		chosen = 53
+	case v, ok = <-chans[54]:
+		chosen = 54
+	case v, ok = <-chans[55]:
+		chosen = 55
+	case v, ok = <-chans[56]:
+		chosen = 56
+	case v, ok = <-chans[57]:
+		chosen = 57
+	case v, ok = <-chans[58]:
+		chosen = 58
+	case v, ok = <-chans[59]:
+		chosen = 59
+	case v, ok = <-chans[60]:
+		chosen = 60
+	case v, ok = <-chans[61]:
+		chosen = 61
+	case v, ok = <-chans[62]:
+		chosen = 62
+	case v, ok = <-chans[63]:
+		chosen = 63
+	}
+	return
+}
+
+func TryRecv4[T ~[4]C, C ~(<-chan E), E any](chans T) (v E, chosen int, ok, received bool) {
+	received = true
+	select {
+	default:
+		received = false
+	case v, ok = <-chans[0]:
+		chosen = 0
+	case v, ok = <-chans[1]:
+		chosen = 1
+	case v, ok = <-chans[2]:
+		chosen = 2
+	case v, ok = <-chans[3]:
+		chosen = 3
+	}
+	return
+}
+
+func TryRecv8[T ~[8]C, C ~(<-chan E), E any](chans T) (v E, chosen int, ok, received bool) {
+	received = true
+	select {
+	default:
+		received = false
+	case v, ok = <-chans[0]:
+		chosen = 0
+	case v, ok = <-chans[1]:
+		chosen = 1
+	case v, ok = <-chans[2]:
+		chosen = 2
+	case v, ok = <-chans[3]:
+		chosen = 3
+	case v, ok = <-chans[4]:
+		chosen = 4
+	case v, ok = <-chans[5]:
+		chosen = 5
+	case v, ok = <-chans[6]:
+		chosen = 6
+	case v, ok = <-chans[7]:
+		chosen = 7
+	}
+	return
+}
+
+func TryRecv16[T ~[16]C, C ~(<-chan E), E any](chans T) (v E, chosen int, ok, received bool) {
+	received = true
+	select {
+	default:
+		received = false
+	case v, ok = <-chans[0]:
+		chosen = 0
+	case v, ok = <-chans[1]:
+		chosen = 1
+	case v, ok = <-chans[2]:
+		chosen = 2
+	case v, ok = <-chans[3]:
+		chosen = 3
+	case v, ok = <-chans[4]:
+		chosen = 4
+	case v, ok = <-chans[5]:
+		chosen = 5
+	case v, ok = <-chans[6]:
+		chosen = 6
+	case v, ok = <-chans[7]:
+		chosen = 7
+	case v, ok = <-chans[8]:
+		chosen = 8
+	case v, ok = <-chans[9]:
+		chosen = 9
+	case v, ok = <-chans[10]:
+		chosen = 10
+	case v, ok = <-chans[11]:
+		chosen = 11
+	case v, ok = <-chans[12]:
+		chosen = 12
+	case v, ok = <-chans[13]:
+		chosen = 13
+	case v, ok = <-chans[14]:
+		chosen = 14
+	case v, ok = <-chans[15]:
+		chosen = 15
+	}
+	return
+}
+
+func TryRecv32[T ~[32]C, C ~(<-chan E), E any](chans T) (v E, chosen int, ok, received bool) {
+	received = true
+	select {
+	default:
+		received = false
+	case v, ok = <-chans[0]:
+		chosen = 0
+	case v, ok = <-chans[1]:
+		chosen = 1
+	case v, ok = <-chans[2]:
+		chosen = 2
+	case v, ok = <-chans[3]:
+		chosen = 3
+	case v, ok = <-chans[4]:
+		chosen = 4
+	case v, ok = <-chans[5]:
+		chosen = 5
+	case v, ok = <-chans[6]:
+		chosen = 6
+	case v, ok = <-chans[7]:
+		chosen = 7
+	case v, ok = <-chans[8]:
+		chosen = 8
+	case v, ok = <-chans[9]:
+		chosen = 9
+	case v, ok = <-chans[10]:
+		chosen = 10
+	case v, ok = <-chans[11]:
+		chosen = 11
+	case v, ok = <-chans[12]:
+		chosen = 12
+	case v, ok = <-chans[13]:
+		chosen = 13
+	case v, ok = <-chans[14]:
+		chosen = 14
+	case v, ok = <-chans[15]:
+		chosen = 15
+	case v, ok = <-chans[16]:
+		chosen = 16
+	case v, ok = <-chans[17]:
+		chosen = 17
+	case v, ok = <-chans[18]:
+		chosen = 18
+	case v, ok = <-chans[19]:
+		chosen = 19
+	case v, ok = <-chans[20]:
+		chosen = 20
+	case v, ok = <-chans[21]:
+		chosen = 21
+	case v, ok = <-chans[22]:
+		chosen = 22
+	case v, ok = <-chans[23]:
+		chosen = 23
+	case v, ok = <-chans[24]:
+		chosen = 24
+	case v, ok = <-chans[25]:
+		chosen = 25
+	case v, ok = <-chans[26]:
+		chosen = 26
+	case v, ok = <-chans[27]:
+		chosen = 27
+	case v, ok = <-chans[28]:
+		chosen = 28
+	case v, ok = <-chans[29]:
+		chosen = 29
+	case v, ok = <-chans[30]:
+		chosen = 30
+	case v, ok = <-chans[31]:
+		chosen = 31
+	}
+	return
+}
+
+func TryRecv64[T ~[64]C, C ~(<-chan E), E any](chans T) (v E, chosen int, ok, received bool) {
+	received = true
+	select {
+	default:
+		received = false
+	case v, ok = <-chans[0]:
+		chosen = 0
+	case v, ok = <-chans[1]:
+		chosen = 1
+	case v, ok = <-chans[2]:
+		chosen = 2
+	case v, ok = <-chans[3]:
+		chosen = 3
+	case v, ok = <-chans[4]:
+		chosen = 4
+	case v, ok = <-chans[5]:
+		chosen = 5
+	case v, ok = <-chans[6]:
+		chosen = 6
+	case v, ok = <-chans[7]:
+		chosen = 7
+	case v, ok = <-chans[8]:
+		chosen = 8
+	case v, ok = <-chans[9]:
+		chosen = 9
+	case v, ok = <-chans[10]:
+		chosen = 10
+	case v, ok = <-chans[11]:
+		chosen = 11
+	case v, ok = <-chans[12]:
+		chosen = 12
+	case v, ok = <-chans[13]:
+		chosen = 13
+	case v, ok = <-chans[14]:
+		chosen = 14
+	case v, ok = <-chans[15]:
+		chosen = 15
+	case v, ok = <-chans[16]:
+		chosen = 16
+	case v, ok = <-chans[17]:
+		chosen = 17
+	case v, ok = <-chans[18]:
+		chosen = 18
+	case v, ok = <-chans[19]:
+		chosen = 19
+	case v, ok = <-chans[20]:
+		chosen = 20
+	case v, ok = <-chans[21]:
+		chosen = 21
+	case v, ok = <-chans[22]:
+		chosen = 22
+	case v, ok = <-chans[23]:
+		chosen = 23
+	case v, ok = <-chans[24]:
+		chosen = 24
+	case v, ok = <-chans[25]:
+		chosen = 25
+	case v, ok = <-chans[26]:
+		chosen = 26
+	case v, ok = <-chans[27]:
+		chosen = 27
+	case v, ok = <-chans[28]:
+		chosen = 28
+	case v, ok = <-chans[29]:
+		chosen = 29
+	case v, ok = <-chans[30]:
+		chosen = 30
+	case v, ok = <-chans[31]:
+		chosen = 31
+	case v, ok = <-chans[32]:
+		chosen = 32
+	case v, ok = <-chans[33]:
+		chosen = 33
+	case v, ok = <-chans[34]:
+		chosen = 34
+	case v, ok = <-chans[35]:
+		chosen = 35
+	case v, ok = <-chans[36]:
+		chosen = 36
+	case v, ok = <-chans[37]:
+		chosen = 37
+	case v, ok = <-chans[38]:
+		chosen = 38
+	case v, ok = <-chans[39]:
+		chosen = 39
+	case v, ok = <-chans[40]:
+		chosen = 40
+	case v, ok = <-chans[41]:
+		chosen = 41
+	case v, ok = <-chans[42]:
+		chosen = 42
+	case v, ok = <-chans[43]:
+		chosen = 43
+	case v, ok = <-chans[44]:
+		chosen = 44
+	case v, ok = <-chans[45]:
+		chosen = 45
+	case v, ok = <-chans[46]:
+		chosen = 46
+	case v, ok = <-chans[47]:
+		chosen = 47
+	case v, ok = <-chans[48]:
+		chosen = 48
+	case v, ok = <-chans[49]:
+		chosen = 49
+	case v, ok = <-chans[50]:
+		chosen = 50
+	case v, ok = <-chans[51]:
+		chosen = 51
+	case v, ok = <-chans[52]:
+		chosen = 52
+	case v, ok = <-chans[53]:
+		chosen = 53
+	case v, ok = <-chans[54]:
+		chosen = 54
+	case v, ok = <-chans[55]:
+		chosen = 55
+	case v, ok = <-chans[56]:
+		chosen = 56
+	case v, ok = <-chans[57]:
+		chosen = 57
+	case v, ok = <-chans[58]:
+		chosen = 58
+	case v, ok = <-chans[59]:
+		chosen = 59
+	case v, ok = <-chans[60]:
+		chosen = 60
+	case v, ok = <-chans[61]:
+		chosen = 61
+	case v, ok = <-chans[62]:
+		chosen = 62
+	case v, ok = <-chans[63]:
+		chosen = 63
+	}
+	return
+}
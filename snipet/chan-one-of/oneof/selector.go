@@ -0,0 +1,116 @@
+package oneof
+
+import "reflect"
+
+// Selector composes send and receive cases of any element types into a single select.
+// Cases are added by AddSend and AddRecv and identified by the order they are added.
+//
+// A Selector with a single case selects without reflection,
+// otherwise it dispatches to reflect.Select.
+// Selector can be reused after Reset.
+type Selector struct {
+	// cases[0] is reserved for cancel.
+	cases    []reflect.SelectCase
+	handlers []func(recv reflect.Value, recvOK bool)
+	singles  []func(cancel <-chan struct{}) bool
+}
+
+func NewSelector() *Selector {
+	return &Selector{
+		cases: []reflect.SelectCase{{Dir: reflect.SelectRecv}},
+	}
+}
+
+// AddSend adds a case sending v to ch. fn, if non-nil, is called when the case is selected.
+func AddSend[E any](s *Selector, ch chan<- E, v E, fn func()) (index int) {
+	s.cases = append(s.cases, reflect.SelectCase{
+		Dir:  reflect.SelectSend,
+		Chan: reflect.ValueOf(ch),
+		// keep static type of v, so that nil interface can be sent.
+		Send: reflect.ValueOf(&v).Elem(),
+	})
+	s.handlers = append(s.handlers, func(reflect.Value, bool) {
+		if fn != nil {
+			fn()
+		}
+	})
+	s.singles = append(s.singles, func(cancel <-chan struct{}) bool {
+		select {
+		case <-cancel:
+			return false
+		case ch <- v:
+		}
+		if fn != nil {
+			fn()
+		}
+		return true
+	})
+	return len(s.handlers) - 1
+}
+
+// AddRecv adds a case receiving from ch. fn, if non-nil, is called with received value when the case is selected.
+// As in "v, ok := <-ch", ok is false if ch is closed.
+func AddRecv[E any](s *Selector, ch <-chan E, fn func(v E, ok bool)) (index int) {
+	s.cases = append(s.cases, reflect.SelectCase{
+		Dir:  reflect.SelectRecv,
+		Chan: reflect.ValueOf(ch),
+	})
+	s.handlers = append(s.handlers, func(recv reflect.Value, recvOK bool) {
+		if fn != nil {
+			// recv is the zero value if the channel is closed;
+			// for interface E, that is a nil interface which can not be asserted.
+			v, _ := recv.Interface().(E)
+			fn(v, recvOK)
+		}
+	})
+	s.singles = append(s.singles, func(cancel <-chan struct{}) bool {
+		var (
+			v  E
+			ok bool
+		)
+		select {
+		case <-cancel:
+			return false
+		case v, ok = <-ch:
+		}
+		if fn != nil {
+			fn(v, ok)
+		}
+		return true
+	})
+	return len(s.handlers) - 1
+}
+
+func (s *Selector) Len() int {
+	return len(s.handlers)
+}
+
+// Reset removes all cases from s.
+func (s *Selector) Reset() {
+	clear(s.cases[1:])
+	clear(s.handlers)
+	clear(s.singles)
+	s.cases = s.cases[:1]
+	s.handlers = s.handlers[:0]
+	s.singles = s.singles[:0]
+}
+
+// Select blocks until one of cases proceeds, then calls its fn.
+// selected is false if cancel is closed before that.
+func (s *Selector) Select(cancel <-chan struct{}) (chosen int, selected bool) {
+	switch len(s.handlers) {
+	case 0:
+		panic("zero cases")
+	case 1:
+		return 0, s.singles[0](cancel)
+	}
+	s.cases[0].Chan = reflect.ValueOf(cancel)
+	chosen, recv, recvOK := reflect.Select(s.cases)
+	s.cases[0].Chan = reflect.Value{}
+	if chosen == 0 {
+		return 0, false
+	}
+	chosen--
+	s.handlers[chosen](recv, recvOK)
+	return chosen, true
+}
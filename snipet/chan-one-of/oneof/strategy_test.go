@@ -0,0 +1,44 @@
+package oneof
+
+import "testing"
+
+func TestRecvEachNeverLosesValuesOnCancel(t *testing.T) {
+	const n, per = 32, 4
+	for range 100 {
+		chans := make([]chan int, n)
+		recvChans := make([]<-chan int, n)
+		for i := range chans {
+			chans[i] = make(chan int, per)
+			for j := range per {
+				chans[i] <- j
+			}
+			recvChans[i] = chans[i]
+		}
+
+		cancel := make(chan struct{})
+		delivered := 0
+		RecvEach(recvChans, func(int, int) {
+			delivered++
+			if delivered == 2 {
+				close(cancel)
+			}
+		}, cancel)
+
+		left := 0
+		for _, ch := range chans {
+			left += len(ch)
+		}
+		if delivered+left != n*per {
+			t.Fatalf("delivered = %d, left = %d, lost = %d", delivered, left, n*per-delivered-left)
+		}
+	}
+}
+
+func TestUnknownStrategyPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("not panicked")
+		}
+	}()
+	RecvWith([]<-chan int{make(chan int)}, nil, Options{Strategy: StrategyFanIn + 1})
+}
@@ -0,0 +1,3 @@
+module cond-wait
+
+go 1.22.0
@@ -1,17 +1,14 @@
 package main
 
 import (
-	"errors"
+	"cond-wait/statemachine"
+	"context"
 	"fmt"
 	"slices"
 	"sync"
 	"time"
 )
 
-var (
-	ErrNotEligibleState = errors.New("not eligible state")
-)
-
 type state int
 
 const (
@@ -21,46 +18,44 @@ const (
 )
 
 type condWorker struct {
-	s    state
-	cond *sync.Cond
+	m *statemachine.StateMachine[state]
 }
 
 func newCondWorker() *condWorker {
 	return &condWorker{
-		s:    stateA,
-		cond: sync.NewCond(&sync.Mutex{}),
+		m: statemachine.New(
+			stateA,
+			statemachine.Transitions[state]{
+				stateA: {stateB},
+				stateB: {stateA, stateC},
+				stateC: {stateA},
+			},
+		),
 	}
 }
 
-func (w *condWorker) changeState(s state) {
-	w.cond.L.Lock()
-	defer w.cond.L.Unlock()
-	w.cond.Broadcast()
-	w.s = s
+func (w *condWorker) changeState(s state) error {
+	return w.m.ChangeState(s)
 }
 
 func (w *condWorker) do(doIf state, waitIf func(state) bool, f func()) error {
-	w.cond.L.Lock()
-	defer w.cond.L.Unlock()
-	if w.s == doIf {
-		f()
-		return nil
-	}
-	if waitIf == nil || !waitIf(w.s) {
-		return ErrNotEligibleState
-	}
-	for {
-		w.cond.Wait() // lock is freed while being blocked on Wait
-		// lock is now held.
-		if w.s == doIf {
-			break
-		}
-		if !waitIf(w.s) {
-			return ErrNotEligibleState
-		}
-	}
-	f()
-	return nil
+	return w.m.Do(doIf, waitIf, f)
+}
+
+func (w *condWorker) doContext(ctx context.Context, doIf state, waitIf func(state) bool, f func()) error {
+	return w.m.DoContext(ctx, doIf, waitIf, f)
+}
+
+func (w *condWorker) subscribe(buf int, policy statemachine.Policy) *statemachine.Subscription[state] {
+	return w.m.Subscribe(buf, policy)
+}
+
+func (w *condWorker) history() []statemachine.Transition[state] {
+	return w.m.History()
+}
+
+func (w *condWorker) waitFor(ctx context.Context, predicate func(state) bool) (state, error) {
+	return w.m.WaitFor(ctx, predicate)
 }
 
 type varSet struct {
@@ -70,6 +65,8 @@ type varSet struct {
 
 func main() {
 	w := newCondWorker()
+	w.m.KeepHistory(3)
+	sub := w.subscribe(1, statemachine.PolicyCoalesce)
 	sChan := make(chan state)
 	doChan := make(chan varSet)
 
@@ -79,7 +76,10 @@ func main() {
 		defer wg.Done()
 		for s := range sChan {
 			fmt.Printf("changing state to %d\n", s)
-			w.changeState(s)
+			if err := w.changeState(s); err != nil {
+				fmt.Printf("failed: %v\n", err)
+				continue
+			}
 			fmt.Printf("changed state to %d\n", s)
 		}
 	}()
@@ -106,7 +106,7 @@ func main() {
 	doChan <- varSet{doIf: stateB}
 	/*
 		doing if 2, would wait if s is one of []
-		done with not eligible state
+		done with not eligible state: observed = 1, expected = 2
 	*/
 	doChan <- varSet{doIf: stateB, waitIf: []state{stateA}}
 	/*
@@ -133,9 +133,40 @@ func main() {
 	/*
 		changing state to 1
 		changed state to 1
-		done with not eligible state
+		done with not eligible state: observed = 1, expected = 3
 	*/
 	close(sChan)
 	close(doChan)
 	wg.Wait()
+
+	fmt.Printf("changeState(stateC) = %v\n", w.changeState(stateC))
+	// changeState(stateC) = illegal transition: from = 1, to = 3
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	err := w.doContext(ctx, stateC, func(s state) bool { return true }, func() { fmt.Println("...working...") })
+	fmt.Printf("doContext = %v\n", err)
+	// doContext = context deadline exceeded
+
+	go func() {
+		time.Sleep(time.Millisecond)
+		_ = w.changeState(stateB)
+	}()
+	s, err := w.waitFor(context.Background(), func(s state) bool { return s == stateB })
+	fmt.Printf("waitFor = %d, %v\n", s, err)
+	// waitFor = 2, <nil>
+
+	sub.Cancel()
+	for t := range sub.C {
+		fmt.Printf("subscribed: %d -> %d, dropped = %d\n", t.From, t.To, sub.Dropped())
+	}
+	// subscribed: 1 -> 2, dropped = 2
+	for _, t := range w.history() {
+		fmt.Printf("history: %d -> %d\n", t.From, t.To)
+	}
+	/*
+		history: 1 -> 2
+		history: 2 -> 1
+		history: 1 -> 2
+	*/
 }
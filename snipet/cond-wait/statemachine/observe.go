@@ -0,0 +1,120 @@
+package statemachine
+
+import (
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+type Transition[S comparable] struct {
+	From S
+	To   S
+	Time time.Time
+}
+
+// Policy decides what to do with a transition when a subscriber's buffer is full.
+type Policy int
+
+const (
+	// PolicyDrop drops the new transition.
+	PolicyDrop Policy = iota
+	// PolicyCoalesce drops the oldest buffered transition to make room for the new one,
+	// so that the subscriber always sees the latest transition.
+	PolicyCoalesce
+)
+
+type Subscription[S comparable] struct {
+	C       <-chan Transition[S]
+	c       chan Transition[S]
+	policy  Policy
+	dropped atomic.Uint64
+	m       *StateMachine[S]
+}
+
+// Dropped returns the number of transitions not delivered to the subscriber.
+func (s *Subscription[S]) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Cancel unsubscribes and closes C. Cancel can be called multiple times.
+func (s *Subscription[S]) Cancel() {
+	s.m.cond.L.Lock()
+	defer s.m.cond.L.Unlock()
+	i := slices.Index(s.m.subs, s)
+	if i < 0 {
+		return
+	}
+	s.m.subs = slices.Delete(s.m.subs, i, i+1)
+	close(s.c)
+}
+
+// notify never blocks.
+// It must be called while holding the lock, to serialize senders.
+func (s *Subscription[S]) notify(t Transition[S]) {
+	for {
+		select {
+		case s.c <- t:
+			return
+		default:
+		}
+		if s.policy == PolicyDrop {
+			s.dropped.Add(1)
+			return
+		}
+		select {
+		case <-s.c:
+			s.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+// Subscribe returns a subscription to transitions made by ChangeState.
+// buf is the capacity of the channel, at least 1.
+// ChangeState never blocks on slow subscribers: transitions overflowing buf are handled by policy.
+func (m *StateMachine[S]) Subscribe(buf int, policy Policy) *Subscription[S] {
+	c := make(chan Transition[S], max(buf, 1))
+	s := &Subscription[S]{
+		C:      c,
+		c:      c,
+		policy: policy,
+		m:      m,
+	}
+	m.cond.L.Lock()
+	defer m.cond.L.Unlock()
+	m.subs = append(m.subs, s)
+	return s
+}
+
+// KeepHistory sets the number of last transitions kept for History.
+// n <= 0 disables history.
+func (m *StateMachine[S]) KeepHistory(n int) {
+	m.cond.L.Lock()
+	defer m.cond.L.Unlock()
+	m.historySize = max(n, 0)
+	if len(m.history) > m.historySize {
+		m.history = slices.Clone(m.history[len(m.history)-m.historySize:])
+	}
+}
+
+// History returns last transitions, oldest first.
+func (m *StateMachine[S]) History() []Transition[S] {
+	m.cond.L.Lock()
+	defer m.cond.L.Unlock()
+	return slices.Clone(m.history)
+}
+
+// observe must be called while holding the lock.
+func (m *StateMachine[S]) observe(t Transition[S]) {
+	if m.historySize > 0 {
+		if len(m.history) == m.historySize {
+			copy(m.history, m.history[1:])
+			m.history[len(m.history)-1] = t
+		} else {
+			m.history = append(m.history, t)
+		}
+	}
+	for _, s := range m.subs {
+		s.notify(t)
+	}
+}
@@ -0,0 +1,111 @@
+package statemachine
+
+import "testing"
+
+// toggle changes the state between stateA and stateB n times.
+func toggle(t *testing.T, m *StateMachine[state], n int) {
+	t.Helper()
+	for range n {
+		s := stateB
+		if m.State() == stateB {
+			s = stateA
+		}
+		if err := m.ChangeState(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// drain returns transitions buffered in sub.C.
+func drain(sub *Subscription[state]) []Transition[state] {
+	var ts []Transition[state]
+	for {
+		select {
+		case t, ok := <-sub.C:
+			if !ok {
+				return ts
+			}
+			ts = append(ts, t)
+		default:
+			return ts
+		}
+	}
+}
+
+func TestSubscribeDrop(t *testing.T) {
+	m := newMachine()
+	sub := m.Subscribe(1, PolicyDrop)
+	// nobody receives, yet ChangeState never blocks.
+	toggle(t, m, 3)
+
+	ts := drain(sub)
+	if len(ts) != 1 || ts[0].From != stateA || ts[0].To != stateB || sub.Dropped() != 2 {
+		t.Fatalf("transitions = %+v, dropped = %d", ts, sub.Dropped())
+	}
+}
+
+func TestSubscribeCoalesce(t *testing.T) {
+	m := newMachine()
+	sub := m.Subscribe(2, PolicyCoalesce)
+	toggle(t, m, 5)
+
+	// the latest two: B -> A, then A -> B.
+	ts := drain(sub)
+	if len(ts) != 2 || ts[0].To != stateA || ts[1].To != stateB || sub.Dropped() != 3 {
+		t.Fatalf("transitions = %+v, dropped = %d", ts, sub.Dropped())
+	}
+}
+
+func TestSubscriptionCancel(t *testing.T) {
+	m := newMachine()
+	sub := m.Subscribe(0, PolicyDrop)
+	other := m.Subscribe(4, PolicyDrop)
+	if cap(sub.C) != 1 {
+		t.Fatalf("cap = %d", cap(sub.C))
+	}
+
+	toggle(t, m, 1)
+	sub.Cancel()
+	sub.Cancel()
+	// a cancelled subscription is no longer sent to.
+	toggle(t, m, 2)
+
+	if ts := drain(sub); len(ts) != 1 {
+		t.Fatalf("transitions = %+v", ts)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Fatal("C is not closed")
+	}
+	if ts := drain(other); len(ts) != 3 {
+		t.Fatalf("other transitions = %+v", ts)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	m := newMachine()
+	if h := m.History(); len(h) != 0 {
+		t.Fatalf("history without KeepHistory = %+v", h)
+	}
+
+	m.KeepHistory(3)
+	toggle(t, m, 5)
+	h := m.History()
+	// A->B, B->A, A->B, B->A, A->B: the last three, oldest first.
+	if len(h) != 3 || h[0].To != stateB || h[1].To != stateA || h[2].To != stateB {
+		t.Fatalf("history = %+v", h)
+	}
+	if h[0].Time.After(h[2].Time) {
+		t.Fatalf("history not oldest first: %+v", h)
+	}
+
+	m.KeepHistory(2)
+	if shrunk := m.History(); len(shrunk) != 2 || shrunk[0] != h[1] || shrunk[1] != h[2] {
+		t.Fatalf("shrunk history = %+v", shrunk)
+	}
+
+	m.KeepHistory(0)
+	toggle(t, m, 1)
+	if h := m.History(); len(h) != 0 {
+		t.Fatalf("disabled history = %+v", h)
+	}
+}
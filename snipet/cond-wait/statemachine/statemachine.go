@@ -0,0 +1,152 @@
+package statemachine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotEligibleState  = errors.New("not eligible state")
+	ErrIllegalTransition = errors.New("illegal transition")
+)
+
+// StateError is returned when the state is not the one expected.
+// It wraps ErrNotEligibleState.
+type StateError[S comparable] struct {
+	Observed S
+	Expected S
+}
+
+func (e *StateError[S]) Error() string {
+	return fmt.Sprintf("%s: observed = %v, expected = %v", ErrNotEligibleState, e.Observed, e.Expected)
+}
+
+func (e *StateError[S]) Unwrap() error {
+	return ErrNotEligibleState
+}
+
+// TransitionError is returned when a transition not declared in Transitions is requested.
+// It wraps ErrIllegalTransition.
+type TransitionError[S comparable] struct {
+	From S
+	To   S
+}
+
+func (e *TransitionError[S]) Error() string {
+	return fmt.Sprintf("%s: from = %v, to = %v", ErrIllegalTransition, e.From, e.To)
+}
+
+func (e *TransitionError[S]) Unwrap() error {
+	return ErrIllegalTransition
+}
+
+// Transitions maps a state to states it is allowed to change to.
+type Transitions[S comparable] map[S][]S
+
+type StateMachine[S comparable] struct {
+	s           S
+	transitions Transitions[S]
+	cond        *sync.Cond
+
+	subs        []*Subscription[S]
+	historySize int
+	history     []Transition[S]
+}
+
+func New[S comparable](initial S, transitions Transitions[S]) *StateMachine[S] {
+	return &StateMachine[S]{
+		s:           initial,
+		transitions: transitions,
+		cond:        sync.NewCond(&sync.Mutex{}),
+	}
+}
+
+func (m *StateMachine[S]) State() S {
+	m.cond.L.Lock()
+	defer m.cond.L.Unlock()
+	return m.s
+}
+
+// ChangeState changes the state to s, wakes up all goroutines waiting in Do
+// and notifies subscribers of the transition.
+// It returns *TransitionError if changing from the current state to s is not declared in the Transitions.
+func (m *StateMachine[S]) ChangeState(s S) error {
+	m.cond.L.Lock()
+	defer m.cond.L.Unlock()
+	if !slices.Contains(m.transitions[m.s], s) {
+		return &TransitionError[S]{From: m.s, To: s}
+	}
+	m.cond.Broadcast()
+	m.observe(Transition[S]{From: m.s, To: s, Time: time.Now()})
+	m.s = s
+	return nil
+}
+
+// Do calls f while the state is doIf.
+// If the state is not doIf, Do waits for it as long as waitIf reports true for the observed state.
+// Otherwise it returns *StateError.
+// waitIf may be nil, which means never to wait.
+func (m *StateMachine[S]) Do(doIf S, waitIf func(S) bool, f func()) error {
+	return m.DoContext(context.Background(), doIf, waitIf, f)
+}
+
+// DoContext is like Do but gives up waiting when ctx is done, returning context.Cause(ctx).
+func (m *StateMachine[S]) DoContext(ctx context.Context, doIf S, waitIf func(S) bool, f func()) error {
+	m.cond.L.Lock()
+	defer m.cond.L.Unlock()
+	if m.s == doIf {
+		f()
+		return nil
+	}
+	if waitIf == nil || !waitIf(m.s) {
+		return &StateError[S]{Observed: m.s, Expected: doIf}
+	}
+	stop := m.wakeOnDone(ctx)
+	defer stop()
+	for {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		m.cond.Wait() // lock is freed while being blocked on Wait
+		// lock is now held.
+		if m.s == doIf {
+			break
+		}
+		if !waitIf(m.s) {
+			return &StateError[S]{Observed: m.s, Expected: doIf}
+		}
+	}
+	f()
+	return nil
+}
+
+// WaitFor blocks until predicate reports true for the state, then returns the state.
+// It returns context.Cause(ctx) if ctx is done before that.
+func (m *StateMachine[S]) WaitFor(ctx context.Context, predicate func(S) bool) (S, error) {
+	m.cond.L.Lock()
+	defer m.cond.L.Unlock()
+	stop := m.wakeOnDone(ctx)
+	defer stop()
+	for !predicate(m.s) {
+		if ctx.Err() != nil {
+			return m.s, context.Cause(ctx)
+		}
+		m.cond.Wait()
+	}
+	return m.s, nil
+}
+
+// wakeOnDone wakes up waiters when ctx is done, so that they can observe ctx.Err.
+// Since Broadcast is done while holding the lock, it never happens
+// between a waiter checking ctx.Err and starting to Wait.
+func (m *StateMachine[S]) wakeOnDone(ctx context.Context) (stop func() bool) {
+	return context.AfterFunc(ctx, func() {
+		m.cond.L.Lock()
+		defer m.cond.L.Unlock()
+		m.cond.Broadcast()
+	})
+}
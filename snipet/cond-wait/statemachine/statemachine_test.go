@@ -0,0 +1,211 @@
+package statemachine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type state int
+
+const (
+	stateA state = iota + 1
+	stateB
+	stateC
+)
+
+func newMachine() *StateMachine[state] {
+	return New(stateA, Transitions[state]{
+		stateA: {stateB},
+		stateB: {stateA, stateC},
+		stateC: {stateA},
+	})
+}
+
+// blocked gives a goroutine started just before time to block in Wait.
+// Tests pass either way, but only exercise waking up if it did.
+func blocked() {
+	time.Sleep(10 * time.Millisecond)
+}
+
+func waitIfIn(states ...state) func(state) bool {
+	return func(s state) bool {
+		for _, w := range states {
+			if s == w {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func TestChangeState(t *testing.T) {
+	m := newMachine()
+	if err := m.ChangeState(stateB); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ChangeState(stateC); err != nil {
+		t.Fatal(err)
+	}
+
+	err := m.ChangeState(stateB)
+	var tErr *TransitionError[state]
+	if !errors.As(err, &tErr) || tErr.From != stateC || tErr.To != stateB {
+		t.Fatalf("err = %v", err)
+	}
+	if !errors.Is(err, ErrIllegalTransition) {
+		t.Fatalf("err = %v, not ErrIllegalTransition", err)
+	}
+	if s := m.State(); s != stateC {
+		t.Fatalf("state = %d after rejected transition", s)
+	}
+	// not declared at all.
+	if err := m.ChangeState(state(10)); !errors.Is(err, ErrIllegalTransition) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestDo(t *testing.T) {
+	m := newMachine()
+
+	var called bool
+	if err := m.Do(stateA, nil, func() { called = true }); err != nil || !called {
+		t.Fatalf("err = %v, called = %t", err, called)
+	}
+
+	called = false
+	err := m.Do(stateB, nil, func() { called = true })
+	var sErr *StateError[state]
+	if !errors.As(err, &sErr) || sErr.Observed != stateA || sErr.Expected != stateB || called {
+		t.Fatalf("err = %v, called = %t", err, called)
+	}
+	if !errors.Is(err, ErrNotEligibleState) {
+		t.Fatalf("err = %v, not ErrNotEligibleState", err)
+	}
+	// waitIf not reporting true for the current state does not wait either.
+	if err := m.Do(stateB, waitIfIn(stateC), func() {}); !errors.As(err, &sErr) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestDoWaits(t *testing.T) {
+	m := newMachine()
+
+	errCh := make(chan error)
+	var called bool
+	go func() {
+		errCh <- m.Do(stateB, waitIfIn(stateA), func() { called = true })
+	}()
+	blocked()
+	if err := m.ChangeState(stateB); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errCh; err != nil || !called {
+		t.Fatalf("err = %v, called = %t", err, called)
+	}
+
+	// the state moves on to one neither doIf nor waited for.
+	called = false
+	go func() {
+		errCh <- m.Do(stateC, waitIfIn(stateB), func() { called = true })
+	}()
+	blocked()
+	if err := m.ChangeState(stateA); err != nil {
+		t.Fatal(err)
+	}
+	var sErr *StateError[state]
+	if err := <-errCh; !errors.As(err, &sErr) || sErr.Observed != stateA || sErr.Expected != stateC || called {
+		t.Fatalf("err = %v, called = %t", err, called)
+	}
+}
+
+func TestDoContextCancel(t *testing.T) {
+	m := newMachine()
+	cause := errors.New("cause")
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	errCh := make(chan error)
+	var called bool
+	go func() {
+		errCh <- m.DoContext(ctx, stateC, waitIfIn(stateA, stateB), func() { called = true })
+	}()
+	blocked()
+	cancel(cause)
+	if err := <-errCh; err != cause || called {
+		t.Fatalf("err = %v, called = %t", err, called)
+	}
+}
+
+func TestDoContextDeadline(t *testing.T) {
+	m := newMachine()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := m.DoContext(ctx, stateC, waitIfIn(stateA), func() { t.Error("called") })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestDoContextWakesOnChangeState(t *testing.T) {
+	m := newMachine()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- m.DoContext(ctx, stateB, waitIfIn(stateA), func() {})
+	}()
+	blocked()
+	if err := m.ChangeState(stateB); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWaitFor(t *testing.T) {
+	m := newMachine()
+	isC := func(s state) bool { return s == stateC }
+
+	type result struct {
+		s   state
+		err error
+	}
+	resCh := make(chan result)
+	go func() {
+		s, err := m.WaitFor(context.Background(), isC)
+		resCh <- result{s, err}
+	}()
+	blocked()
+	// a state not satisfying the predicate keeps it waiting.
+	if err := m.ChangeState(stateB); err != nil {
+		t.Fatal(err)
+	}
+	blocked()
+	if err := m.ChangeState(stateC); err != nil {
+		t.Fatal(err)
+	}
+	if r := <-resCh; r.s != stateC || r.err != nil {
+		t.Fatalf("WaitFor = %d, %v", r.s, r.err)
+	}
+
+	cause := errors.New("cause")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	go func() {
+		s, err := m.WaitFor(ctx, waitIfIn(stateB))
+		resCh <- result{s, err}
+	}()
+	blocked()
+	cancel(cause)
+	if r := <-resCh; r.s != stateC || r.err != cause {
+		t.Fatalf("WaitFor = %d, %v", r.s, r.err)
+	}
+
+	ctx, cancelTimeout := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelTimeout()
+	if _, err := m.WaitFor(ctx, waitIfIn(stateB)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package atomicfile
+
+import (
+	"errors"
+	"io-copy-file/wfs"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+)
+
+var ErrClosed = errors.New("atomicfile: already committed or aborted")
+
+type Options struct {
+	// Perm is the permission of the file. 0o644 if zero.
+	Perm fs.FileMode
+	// PreservePerm makes the file take over the permission of the existing file at the path, if any.
+	PreservePerm bool
+}
+
+// Writer writes into a temporary file in the same directory as the target path,
+// which replaces the target only on Commit.
+// A crash before Commit leaves the target intact, possibly with a stray temporary file.
+type Writer struct {
+	fsys wfs.WritableFS
+	f    wfs.File
+	// dir is prepended to names in fsys to report Name.
+	dir       string
+	name, tmp string
+	perm      fs.FileMode
+	closed    bool
+	// release is called once the writer is committed or aborted.
+	release func() error
+}
+
+// Create is CreateFS on the OS directory of path.
+// With PreservePerm, the existing file is stat'ed through os.Stat,
+// so a symlink at path is followed even if it points outside of the directory.
+func Create(path string, opts Options) (*Writer, error) {
+	if opts.PreservePerm {
+		info, err := os.Stat(path)
+		switch {
+		case err == nil:
+			opts.Perm = info.Mode().Perm()
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, err
+		}
+		opts.PreservePerm = false
+	}
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	fsys, err := wfs.OpenOS(dir)
+	if err != nil {
+		return nil, err
+	}
+	w, err := CreateFS(fsys, base, opts)
+	if err != nil {
+		_ = fsys.Close()
+		return nil, err
+	}
+	w.dir = dir
+	w.release = fsys.Close
+	return w, nil
+}
+
+// CreateFS returns a Writer which replaces name in fsys.
+func CreateFS(fsys wfs.WritableFS, name string, opts Options) (*Writer, error) {
+	perm := opts.Perm
+	if perm == 0 {
+		perm = 0o644
+	}
+	if opts.PreservePerm {
+		info, err := fs.Stat(fsys, name)
+		switch {
+		case err == nil:
+			perm = info.Mode().Perm()
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, err
+		}
+	}
+
+	dir, base := path.Split(name)
+	for range 10000 {
+		tmp := dir + "." + base + ".tmp-" + strconv.FormatUint(rand.Uint64(), 36)
+		f, err := fsys.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &Writer{
+			fsys:    fsys,
+			f:       f,
+			name:    name,
+			tmp:     tmp,
+			perm:    perm,
+			release: func() error { return nil },
+		}, nil
+	}
+	return nil, &fs.PathError{Op: "createtemp", Path: name, Err: fs.ErrExist}
+}
+
+// File returns the underlying temporary file,
+// e.g. to let copies use fast paths of *os.File, which is its type if created by Create.
+// It must not be closed by the caller.
+func (w *Writer) File() wfs.File {
+	return w.f
+}
+
+// Name returns the name of the temporary file.
+func (w *Writer) Name() string {
+	if w.dir == "" {
+		return w.tmp
+	}
+	return filepath.Join(w.dir, filepath.FromSlash(w.tmp))
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.f.Write(p)
+}
+
+func (w *Writer) WriteAt(p []byte, off int64) (int, error) {
+	return w.f.WriteAt(p, off)
+}
+
+// Commit fsyncs the temporary file, renames it to the target path
+// and fsyncs the parent directory so that the rename itself is durable.
+// The temporary file is removed if Commit fails.
+func (w *Writer) Commit() (err error) {
+	if w.closed {
+		return ErrClosed
+	}
+	w.closed = true
+	defer func() {
+		if err != nil {
+			_ = w.fsys.Remove(w.tmp)
+		}
+		err = errors.Join(err, w.release())
+	}()
+
+	if err := w.f.Chmod(w.perm); err != nil {
+		_ = w.f.Close()
+		return err
+	}
+	if err := w.f.Sync(); err != nil {
+		_ = w.f.Close()
+		return err
+	}
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	if err := w.fsys.Rename(w.tmp, w.name); err != nil {
+		return err
+	}
+	return syncDir(w.fsys, path.Dir(w.name))
+}
+
+// Abort discards the temporary file.
+// It is a no-op after Commit, so it can be deferred right after Create.
+func (w *Writer) Abort() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	err := w.f.Close()
+	return errors.Join(err, w.fsys.Remove(w.tmp), w.release())
+}
+
+func syncDir(fsys wfs.WritableFS, dir string) error {
+	d, err := fsys.OpenFile(dir, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,129 @@
+package atomicfile
+
+import (
+	"errors"
+	"io-copy-file/wfs"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateFSCommit(t *testing.T) {
+	fsys := wfs.NewMem()
+	w, err := CreateFS(fsys, "foo", Options{Perm: 0o600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("foo")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fs.Stat(fsys, "foo"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("visible before commit: %v", err)
+	}
+	if err := w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Commit(); !errors.Is(err, ErrClosed) {
+		t.Fatalf("second commit: %v", err)
+	}
+	if err := w.Abort(); err != nil {
+		t.Fatalf("abort after commit: %v", err)
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("entries = %v, %v", entries, err)
+	}
+	info, err := entries[0].Info()
+	if err != nil || info.Name() != "foo" || info.Mode() != 0o600 {
+		t.Fatalf("info = %v, %v", info, err)
+	}
+	if b, err := fs.ReadFile(fsys, "foo"); err != nil || string(b) != "foo" {
+		t.Fatalf("content = %q, %v", b, err)
+	}
+}
+
+func TestCreateFSAbort(t *testing.T) {
+	fsys := wfs.NewMem()
+	writeFile(t, fsys, "foo", "old")
+	w, err := CreateFS(fsys, "foo", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Abort(); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := fs.ReadFile(fsys, "foo"); err != nil || string(b) != "old" {
+		t.Fatalf("content = %q, %v", b, err)
+	}
+	if entries, _ := fs.ReadDir(fsys, "."); len(entries) != 1 {
+		t.Fatalf("temporary file left: %v", entries)
+	}
+}
+
+func TestCreateFSPreservePerm(t *testing.T) {
+	fsys := wfs.NewMem()
+	writeFile(t, fsys, "foo", "old")
+	f, err := fsys.OpenFile("foo", os.O_RDONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := errors.Join(f.Chmod(0o640), f.Close()); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := CreateFS(fsys, "foo", Options{Perm: 0o600, PreservePerm: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := fs.Stat(fsys, "foo"); err != nil || info.Mode() != 0o640 {
+		t.Fatalf("info = %v, %v", info, err)
+	}
+}
+
+func TestCreatePreservePermEscapingSymlink(t *testing.T) {
+	target := filepath.Join(t.TempDir(), "target")
+	if err := os.WriteFile(target, nil, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(target, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(t.TempDir(), "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := Create(link, Options{PreservePerm: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// the link itself is replaced.
+	if info, err := os.Lstat(link); err != nil || info.Mode() != 0o640 {
+		t.Fatalf("info = %v, %v", info, err)
+	}
+}
+
+func writeFile(t *testing.T, fsys wfs.WritableFS, name, content string) {
+	t.Helper()
+	f, err := fsys.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -1,3 +1,11 @@
 module io-copy-file
 
-go 1.22.0
+go 1.25.0
+
+require (
+	buf-pool v0.0.0
+	golang.org/x/sync v0.7.0
+	golang.org/x/sys v0.22.0
+)
+
+replace buf-pool => ../buf-pool
@@ -0,0 +1,174 @@
+package iocopy
+
+import (
+	"buf-pool/bufpool"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// bufSize is the size of buffers taken from bufpool.
+const bufSize = 32 * 1024
+
+type Progress struct {
+	Written int64
+	// Total is the size of src, or -1 if unknown.
+	Total   int64
+	Elapsed time.Duration
+	// Rate is the average throughput in bytes per second.
+	Rate float64
+	// ETA is the estimated remaining time, or -1 if unknown.
+	ETA time.Duration
+}
+
+type Options struct {
+	// Progress, if non-nil, is called every Interval and once more when the copy ends.
+	// It is called from a goroutine other than the caller's, but never concurrently.
+	Progress func(p Progress)
+	// Interval defaults to 1s.
+	Interval time.Duration
+	// Size is the total size of src, used to compute ETA.
+	// If zero, it is taken from Stat of src when src is a regular file.
+	Size int64
+}
+
+type readDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+type writeDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// CopyContext copies from src to dst until EOF, an error or ctx is done.
+// When ctx is done, it returns context.Cause(ctx) along with bytes written so far.
+//
+// Blocking reads and writes are interrupted by setting past deadlines on src and dst
+// if they implement SetReadDeadline / SetWriteDeadline, e.g. *os.File of pipes or net.Conn.
+// Those deadlines are left as is. Regular files do not support deadlines,
+// but reads and writes on them do not block indefinitely either.
+func CopyContext(ctx context.Context, dst io.Writer, src io.Reader, opts Options) (written int64, err error) {
+	if ctx.Err() != nil {
+		return 0, context.Cause(ctx)
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		past := time.Unix(1, 0)
+		if d, ok := src.(readDeadliner); ok {
+			_ = d.SetReadDeadline(past)
+		}
+		if d, ok := dst.(writeDeadliner); ok {
+			_ = d.SetWriteDeadline(past)
+		}
+	})
+	defer stop()
+
+	var counter atomic.Int64
+	if opts.Progress != nil {
+		stopReporting := startReporting(&counter, totalSize(src, opts.Size), opts)
+		defer stopReporting()
+	}
+
+	buf := bufpool.Get(bufSize)
+	defer bufpool.Put(buf)
+
+	for {
+		if ctx.Err() != nil {
+			return counter.Load(), context.Cause(ctx)
+		}
+		nr, er := src.Read(*buf)
+		if nr > 0 {
+			nw, ew := dst.Write((*buf)[:nr])
+			counter.Add(int64(nw))
+			if ew == nil && nw != nr {
+				ew = io.ErrShortWrite
+			}
+			if ew != nil {
+				return counter.Load(), cancelledOr(ctx, ew)
+			}
+		}
+		if er == io.EOF {
+			return counter.Load(), nil
+		}
+		if er != nil {
+			return counter.Load(), cancelledOr(ctx, er)
+		}
+	}
+}
+
+// cancelledOr replaces errors caused by deadlines set on cancellation.
+func cancelledOr(ctx context.Context, err error) error {
+	if ctx.Err() != nil && errors.Is(err, os.ErrDeadlineExceeded) {
+		return context.Cause(ctx)
+	}
+	return err
+}
+
+func totalSize(src io.Reader, size int64) int64 {
+	if size > 0 {
+		return size
+	}
+	if s, ok := src.(interface{ Stat() (fs.FileInfo, error) }); ok {
+		info, err := s.Stat()
+		if err == nil && info.Mode().IsRegular() {
+			return info.Size()
+		}
+	}
+	return -1
+}
+
+// startReporting calls opts.Progress every opts.Interval in a new goroutine.
+// The returned func stops the goroutine, then reports the last progress.
+func startReporting(counter *atomic.Int64, total int64, opts Options) (stop func()) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	start := time.Now()
+	report := func() {
+		opts.Progress(makeProgress(counter.Load(), total, time.Since(start)))
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				report()
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+		report()
+	}
+}
+
+func makeProgress(written, total int64, elapsed time.Duration) Progress {
+	p := Progress{
+		Written: written,
+		Total:   total,
+		Elapsed: elapsed,
+		ETA:     -1,
+	}
+	if elapsed > 0 {
+		p.Rate = float64(written) / elapsed.Seconds()
+	}
+	if total >= 0 && p.Rate > 0 {
+		p.ETA = time.Duration(float64(max(total-written, 0)) / p.Rate * float64(time.Second))
+	}
+	return p
+}
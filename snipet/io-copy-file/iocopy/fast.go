@@ -0,0 +1,73 @@
+package iocopy
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// Mechanism tells how Copy moved bytes.
+type Mechanism int
+
+const (
+	// MechanismBuffer copied through a pooled buffer in user space.
+	MechanismBuffer Mechanism = iota + 1
+	// MechanismCopyFileRange let the kernel copy by copy_file_range(2).
+	MechanismCopyFileRange
+	// MechanismSendfile let the kernel copy by sendfile(2).
+	MechanismSendfile
+	// MechanismNone copied no data, since src was empty or had only holes at and after its offset.
+	MechanismNone
+)
+
+func (m Mechanism) String() string {
+	switch m {
+	case MechanismBuffer:
+		return "buffer"
+	case MechanismCopyFileRange:
+		return "copy_file_range"
+	case MechanismSendfile:
+		return "sendfile"
+	case MechanismNone:
+		return "none"
+	default:
+		return "unknown"
+	}
+}
+
+type Result struct {
+	// Written is the number of bytes src and dst advanced by, including holes.
+	Written int64
+	// Mechanism is the one which copied the data, or the last one if the kernel switched in the middle.
+	Mechanism Mechanism
+	// Holes is the number of bytes skipped as holes of a sparse src.
+	// Holes are preserved only if dst has no data at and after its offset.
+	Holes int64
+}
+
+// Copy copies from src to dst like io.Copy, but stops when ctx is done, as CopyContext does.
+//
+// If both src and dst are regular *os.File, the copy is done by the kernel where supported,
+// from the current offset of src to the current offset of dst, skipping holes of src.
+// Otherwise, or if the kernel refuses, Copy falls back to CopyContext's pooled buffer.
+// Result.Mechanism tells which was used.
+func Copy(ctx context.Context, dst io.Writer, src io.Reader) (Result, error) {
+	if ctx.Err() != nil {
+		return Result{}, context.Cause(ctx)
+	}
+	if s, ok := src.(*os.File); ok {
+		if d, ok := dst.(*os.File); ok && isRegular(s) && isRegular(d) {
+			result, handled, err := copyFiles(ctx, d, s)
+			if handled {
+				return result, err
+			}
+		}
+	}
+	n, err := CopyContext(ctx, dst, src, Options{})
+	return Result{Written: n, Mechanism: MechanismBuffer}, err
+}
+
+func isRegular(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode().IsRegular()
+}
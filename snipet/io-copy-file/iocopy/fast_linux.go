@@ -0,0 +1,163 @@
+package iocopy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// chunk is the size passed to a single syscall, to check ctx in between.
+const chunk = 8 * 1024 * 1024
+
+// copyFiles copies data segments of src by copy_file_range, or by sendfile if it is not supported.
+// handled is false if neither is usable and nothing has been copied,
+// in which case offsets of src and dst are left where they were.
+func copyFiles(ctx context.Context, dst, src *os.File) (result Result, handled bool, err error) {
+	// Neither syscall writes at the end of an O_APPEND dst. os.(*File).ReadFrom gives up on it too.
+	if flag, err := unix.FcntlInt(dst.Fd(), unix.F_GETFL, 0); err != nil || flag&unix.O_APPEND != 0 {
+		return result, false, nil
+	}
+	srcOff, err := src.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return result, false, nil
+	}
+	dstOff, err := dst.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return result, false, nil
+	}
+	srcInfo, err := src.Stat()
+	if err != nil {
+		return result, false, nil
+	}
+	dstInfo, err := dst.Stat()
+	if err != nil {
+		return result, false, nil
+	}
+	size := srcInfo.Size()
+	// Skipping a hole leaves dst as is. That is zeros only if dst has nothing there.
+	sparse := dstInfo.Size() <= dstOff
+
+	// written tracks the src offset up to which the copy is done.
+	written := srcOff
+	// mech is the mechanism to try next. result.Mechanism is set only once it has copied data.
+	mech := MechanismCopyFileRange
+	result.Mechanism = MechanismNone
+	defer func() {
+		if !handled {
+			// nextData and sendfile may have moved them.
+			_, errSrc := src.Seek(srcOff, io.SeekStart)
+			_, errDst := dst.Seek(dstOff, io.SeekStart)
+			if err = errors.Join(errSrc, errDst); err != nil {
+				handled = true
+			}
+			return
+		}
+		result.Written = written - srcOff
+		// Leave offsets where io.Copy would.
+		_, errSrc := src.Seek(written, io.SeekStart)
+		_, errDst := dst.Seek(dstOff+result.Written, io.SeekStart)
+		err = errors.Join(err, errSrc, errDst)
+	}()
+
+	for written < size {
+		if ctx.Err() != nil {
+			return result, true, context.Cause(ctx)
+		}
+
+		start, end := written, size
+		if sparse {
+			start, end, err = nextData(src, written, size)
+			if err != nil {
+				return result, true, err
+			}
+			result.Holes += start - written
+			written = start
+			if start == end {
+				break
+			}
+		}
+
+		for written < end {
+			if ctx.Err() != nil {
+				return result, true, context.Cause(ctx)
+			}
+			n, err := copyRange(&mech, dst, src, written, dstOff+(written-srcOff), int(min(end-written, chunk)))
+			if n == 0 && err == nil {
+				// src shrank.
+				size = written
+				break
+			}
+			if err != nil {
+				if result.Mechanism == MechanismNone && mech == 0 {
+					return result, false, nil
+				}
+				return result, true, err
+			}
+			result.Mechanism = mech
+			written += int64(n)
+		}
+	}
+
+	if sparse && written > srcOff {
+		// A trailing hole is not written by anyone. Extend dst to cover it.
+		if err := dst.Truncate(dstOff + (written - srcOff)); err != nil {
+			return result, true, err
+		}
+	}
+	return result, true, nil
+}
+
+// nextData returns the data segment at or after off. start == end == size if none left.
+func nextData(f *os.File, off, size int64) (start, end int64, err error) {
+	start, err = unix.Seek(int(f.Fd()), off, unix.SEEK_DATA)
+	if errors.Is(err, unix.ENXIO) {
+		// no more data, the rest is a hole.
+		return size, size, nil
+	}
+	if errors.Is(err, unix.EINVAL) {
+		// SEEK_DATA is not supported by the filesystem. Treat all as data.
+		return off, size, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err = unix.Seek(int(f.Fd()), start, unix.SEEK_HOLE)
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, min(end, size), nil
+}
+
+// copyRange is a variable to let tests force the fallback.
+var copyRange = copyKernel
+
+// copyKernel copies up to n bytes from srcOff of src to dstOff of dst by mech.
+// It switches mech to sendfile, or to zero if neither is usable.
+func copyKernel(mech *Mechanism, dst, src *os.File, srcOff, dstOff int64, n int) (int, error) {
+	if *mech == MechanismCopyFileRange {
+		roff, woff := srcOff, dstOff
+		written, err := unix.CopyFileRange(int(src.Fd()), &roff, int(dst.Fd()), &woff, n, 0)
+		if err == nil {
+			return written, nil
+		}
+		if !errors.Is(err, unix.EXDEV) && !errors.Is(err, unix.ENOSYS) &&
+			!errors.Is(err, unix.EOPNOTSUPP) && !errors.Is(err, unix.EINVAL) {
+			return 0, err
+		}
+		*mech = MechanismSendfile
+	}
+
+	// sendfile writes at the current offset of dst.
+	if _, err := dst.Seek(dstOff, io.SeekStart); err != nil {
+		return 0, err
+	}
+	roff := srcOff
+	written, err := unix.Sendfile(int(dst.Fd()), int(src.Fd()), &roff, n)
+	if err != nil && (errors.Is(err, unix.ENOSYS) || errors.Is(err, unix.EINVAL)) {
+		*mech = 0
+	}
+	return written, err
+}
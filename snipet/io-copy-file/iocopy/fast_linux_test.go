@@ -0,0 +1,192 @@
+package iocopy
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func createFile(t *testing.T, name string) *os.File {
+	t.Helper()
+	f, err := os.Create(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	return f
+}
+
+func copyAndRead(t *testing.T, src *os.File) (Result, []byte) {
+	t.Helper()
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	dst := createFile(t, "dst")
+	result, err := Copy(context.Background(), dst, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(dst.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result, b
+}
+
+func TestCopyMechanism(t *testing.T) {
+	t.Run("plain", func(t *testing.T) {
+		src := createFile(t, "src")
+		data := bytes.Repeat([]byte("0123456789abcdef"), 64*1024)
+		if _, err := src.Write(data); err != nil {
+			t.Fatal(err)
+		}
+		result, got := copyAndRead(t, src)
+		if result.Mechanism != MechanismCopyFileRange || result.Written != int64(len(data)) || result.Holes != 0 {
+			t.Fatalf("result = %+v", result)
+		}
+		if !bytes.Equal(got, data) {
+			t.Fatal("content mismatch")
+		}
+	})
+
+	t.Run("sparse", func(t *testing.T) {
+		const size, off = 16 * 1024 * 1024, 8 * 1024 * 1024
+		src := createFile(t, "src")
+		if err := src.Truncate(size); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := src.WriteAt([]byte("data"), off); err != nil {
+			t.Fatal(err)
+		}
+		if !isSparse(t, src) {
+			t.Skip("the filesystem of t.TempDir does not make sparse files")
+		}
+		result, got := copyAndRead(t, src)
+		if result.Mechanism != MechanismCopyFileRange || result.Written != size || result.Holes == 0 {
+			t.Fatalf("result = %+v", result)
+		}
+		if len(got) != size || string(got[off:off+4]) != "data" {
+			t.Fatal("content mismatch")
+		}
+	})
+
+	t.Run("all hole", func(t *testing.T) {
+		const size = 1024 * 1024
+		src := createFile(t, "src")
+		if err := src.Truncate(size); err != nil {
+			t.Fatal(err)
+		}
+		if !isSparse(t, src) {
+			t.Skip("the filesystem of t.TempDir does not make sparse files")
+		}
+		result, got := copyAndRead(t, src)
+		if result.Mechanism != MechanismNone || result.Written != size || result.Holes != size {
+			t.Fatalf("result = %+v", result)
+		}
+		if len(got) != size {
+			t.Fatalf("len = %d", len(got))
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		result, got := copyAndRead(t, createFile(t, "src"))
+		if result.Mechanism != MechanismNone || result.Written != 0 || len(got) != 0 {
+			t.Fatalf("result = %+v, len = %d", result, len(got))
+		}
+	})
+
+	t.Run("pipe", func(t *testing.T) {
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer pr.Close()
+		go func() {
+			_, _ = pw.Write([]byte("foobar"))
+			_ = pw.Close()
+		}()
+		dst := createFile(t, "dst")
+		result, err := Copy(context.Background(), dst, pr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Mechanism != MechanismBuffer || result.Written != 6 {
+			t.Fatalf("result = %+v", result)
+		}
+	})
+}
+
+func TestCopyFallback(t *testing.T) {
+	t.Run("append", func(t *testing.T) {
+		src := createFile(t, "src")
+		if _, err := src.Write([]byte("foobar")); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		name := filepath.Join(t.TempDir(), "dst")
+		if err := os.WriteFile(name, []byte("head"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		dst, err := os.OpenFile(name, os.O_RDWR|os.O_APPEND, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer dst.Close()
+
+		result, err := Copy(context.Background(), dst, src)
+		if err != nil || result.Mechanism != MechanismBuffer || result.Written != 6 {
+			t.Fatalf("result = %+v, err = %v", result, err)
+		}
+		if b, err := os.ReadFile(name); err != nil || string(b) != "headfoobar" {
+			t.Fatalf("content = %q, %v", b, err)
+		}
+	})
+
+	t.Run("after hole", func(t *testing.T) {
+		const size, off = 4 * 1024 * 1024, 2 * 1024 * 1024
+		src := createFile(t, "src")
+		if err := src.Truncate(size); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := src.WriteAt([]byte("data"), off); err != nil {
+			t.Fatal(err)
+		}
+		if !isSparse(t, src) {
+			t.Skip("the filesystem of t.TempDir does not make sparse files")
+		}
+
+		copyRange = func(mech *Mechanism, dst, src *os.File, srcOff, dstOff int64, n int) (int, error) {
+			// as if neither syscall were supported, after dst was moved for sendfile.
+			*mech = 0
+			if _, err := dst.Seek(dstOff, io.SeekStart); err != nil {
+				return 0, err
+			}
+			return 0, syscall.EINVAL
+		}
+		defer func() { copyRange = copyKernel }()
+
+		result, got := copyAndRead(t, src)
+		if result.Mechanism != MechanismBuffer || result.Written != size {
+			t.Fatalf("result = %+v", result)
+		}
+		if len(got) != size || string(got[off:off+4]) != "data" {
+			t.Fatal("content mismatch")
+		}
+	})
+}
+
+func isSparse(t *testing.T, f *os.File) bool {
+	t.Helper()
+	info, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := info.Sys().(*syscall.Stat_t)
+	return st.Blocks*512 < info.Size()
+}
@@ -0,0 +1,206 @@
+package iocopy
+
+import (
+	"buf-pool/bufpool"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"hash"
+	"io"
+	"io-copy-file/atomicfile"
+	"io-copy-file/wfs"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const CheckpointSuffix = ".checkpoint"
+
+// checkpoint is stored next to the destination as JSON.
+type checkpoint struct {
+	Offset int64 `json:"offset"`
+	// Sum is SHA-256 of the destination's first Offset bytes.
+	Sum []byte `json:"sum"`
+	// SrcSize and SrcModTime detect the source changed between runs.
+	SrcSize    int64     `json:"src_size"`
+	SrcModTime time.Time `json:"src_mod_time"`
+}
+
+type ResumeOptions struct {
+	// Interval is the number of bytes copied between checkpoints. 64MiB if zero.
+	Interval int64
+}
+
+type ResumeResult struct {
+	// ResumedFrom is the offset the copy started from. Zero if no valid checkpoint was found.
+	ResumedFrom int64
+	Written     int64
+	// Sum is SHA-256 of the whole destination. Only set when the copy completes.
+	Sum []byte
+}
+
+// CopyResumable is CopyResumableFS from srcPath to dstPath on the OS.
+// If dstPath is a symlink, the file it points to is written and the checkpoint is placed next to it.
+func CopyResumable(ctx context.Context, dstPath, srcPath string, opts ResumeOptions) (ResumeResult, error) {
+	if resolved, err := filepath.EvalSymlinks(dstPath); err == nil {
+		dstPath = resolved
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return ResumeResult{}, err
+	}
+	dst, err := wfs.OpenOS(filepath.Dir(dstPath))
+	if err != nil {
+		return ResumeResult{}, err
+	}
+	defer dst.Close()
+	src := os.DirFS(filepath.Dir(srcPath))
+	return CopyResumableFS(ctx, dst, filepath.Base(dstPath), src, filepath.Base(srcPath), opts)
+}
+
+// CopyResumableFS copies srcName of src to dstName of dst, recording a checkpoint at dstName + CheckpointSuffix
+// every opts.Interval bytes and when ctx is done.
+// The source file must implement io.ReaderAt, as *os.File does.
+//
+// If a checkpoint is found, it is verified against the source's size and mtime
+// and against the checksum of the destination's prefix, then the copy continues from its offset.
+// Otherwise the copy starts over from zero.
+// The checkpoint is removed once the copy completes.
+func CopyResumableFS(ctx context.Context, dst wfs.WritableFS, dstName string, src fs.FS, srcName string, opts ResumeOptions) (result ResumeResult, err error) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 64 * 1024 * 1024
+	}
+
+	srcFile, err := src.Open(srcName)
+	if err != nil {
+		return result, err
+	}
+	defer srcFile.Close()
+	r, ok := srcFile.(io.ReaderAt)
+	if !ok {
+		return result, &fs.PathError{Op: "read", Path: srcName, Err: errors.ErrUnsupported}
+	}
+	srcInfo, err := srcFile.Stat()
+	if err != nil {
+		return result, err
+	}
+
+	w, err := dst.OpenFile(dstName, os.O_RDWR|os.O_CREATE, srcInfo.Mode().Perm())
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		err = errors.Join(err, w.Close())
+	}()
+
+	cpName := dstName + CheckpointSuffix
+	offset, sum, err := resumePoint(dst, cpName, w, srcInfo)
+	if err != nil {
+		return result, err
+	}
+	result.ResumedFrom = offset
+
+	save := func() error {
+		if err := w.Sync(); err != nil {
+			return err
+		}
+		return writeCheckpoint(dst, cpName, checkpoint{
+			Offset:     offset,
+			Sum:        sum.Sum(nil),
+			SrcSize:    srcInfo.Size(),
+			SrcModTime: srcInfo.ModTime(),
+		})
+	}
+
+	buf := bufpool.Get(bufSize)
+	defer bufpool.Put(buf)
+
+	lastSaved := offset
+	for {
+		if ctx.Err() != nil {
+			result.Written = offset - result.ResumedFrom
+			return result, errors.Join(context.Cause(ctx), save())
+		}
+		nr, er := r.ReadAt(*buf, offset)
+		if nr > 0 {
+			nw, ew := w.WriteAt((*buf)[:nr], offset)
+			_, _ = sum.Write((*buf)[:nw])
+			offset += int64(nw)
+			if ew != nil {
+				result.Written = offset - result.ResumedFrom
+				return result, errors.Join(ew, save())
+			}
+		}
+		if er == io.EOF {
+			break
+		}
+		if er != nil {
+			result.Written = offset - result.ResumedFrom
+			return result, errors.Join(er, save())
+		}
+		if offset-lastSaved >= interval {
+			if err := save(); err != nil {
+				return result, err
+			}
+			lastSaved = offset
+		}
+	}
+
+	result.Written = offset - result.ResumedFrom
+	// dst may be longer if it existed before without a checkpoint.
+	if err := w.Truncate(offset); err != nil {
+		return result, err
+	}
+	if err := w.Sync(); err != nil {
+		return result, err
+	}
+	if err := dst.Remove(cpName); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return result, err
+	}
+	result.Sum = sum.Sum(nil)
+	return result, nil
+}
+
+// resumePoint returns the offset to resume from and the hash of the destination's prefix up to it.
+// An absent, unreadable or stale checkpoint results in zero offset.
+func resumePoint(fsys fs.FS, cpName string, dst io.ReaderAt, srcInfo fs.FileInfo) (int64, hash.Hash, error) {
+	sum := sha256.New()
+
+	bin, err := fs.ReadFile(fsys, cpName)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return 0, nil, err
+	}
+	var cp checkpoint
+	if err != nil || json.Unmarshal(bin, &cp) != nil ||
+		cp.SrcSize != srcInfo.Size() || !cp.SrcModTime.Equal(srcInfo.ModTime()) ||
+		cp.Offset < 0 || cp.Offset > cp.SrcSize {
+		return 0, sum, nil
+	}
+
+	n, err := io.Copy(sum, io.NewSectionReader(dst, 0, cp.Offset))
+	if err != nil {
+		return 0, nil, err
+	}
+	if n != cp.Offset || !slices.Equal(sum.Sum(nil), cp.Sum) {
+		return 0, sha256.New(), nil
+	}
+	return cp.Offset, sum, nil
+}
+
+func writeCheckpoint(fsys wfs.WritableFS, name string, cp checkpoint) error {
+	bin, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	w, err := atomicfile.CreateFS(fsys, name, atomicfile.Options{})
+	if err != nil {
+		return err
+	}
+	defer w.Abort()
+	if _, err := w.Write(bin); err != nil {
+		return err
+	}
+	return w.Commit()
+}
@@ -0,0 +1,110 @@
+package iocopy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io-copy-file/wfs"
+	"io/fs"
+	"os"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+var errInjected = errors.New("injected")
+
+// failingFS fails reads of its files at or beyond limit.
+type failingFS struct {
+	fs.FS
+	limit int64
+}
+
+func (f failingFS) Open(name string) (fs.File, error) {
+	file, err := f.FS.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return failingFile{file, f.limit}, nil
+}
+
+type failingFile struct {
+	fs.File
+	limit int64
+}
+
+func (f failingFile) ReadAt(p []byte, off int64) (int, error) {
+	if off >= f.limit {
+		return 0, errInjected
+	}
+	return f.File.(io.ReaderAt).ReadAt(p, off)
+}
+
+func TestCopyResumableFS(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 16*1024)
+	src := fstest.MapFS{"src": {Data: data, Mode: 0o640, ModTime: treeModTime}}
+	dst := wfs.NewMem()
+	opts := ResumeOptions{Interval: bufSize}
+
+	// reads at 0, 1 and 2 * bufSize succeed.
+	result, err := CopyResumableFS(context.Background(), dst, "dst", failingFS{src, 3 * bufSize}, "src", opts)
+	if !errors.Is(err, errInjected) || result.ResumedFrom != 0 || result.Written != 3*bufSize {
+		t.Fatalf("first: result = %+v, err = %v", result, err)
+	}
+	if _, err := fs.Stat(dst, "dst"+CheckpointSuffix); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err = CopyResumableFS(context.Background(), dst, "dst", src, "src", opts)
+	if err != nil || result.ResumedFrom != 3*bufSize || result.Written != int64(len(data))-3*bufSize {
+		t.Fatalf("second: result = %+v, err = %v", result, err)
+	}
+	want := sha256.Sum256(data)
+	if !slices.Equal(result.Sum, want[:]) {
+		t.Fatal("sum mismatch")
+	}
+	if b, err := fs.ReadFile(dst, "dst"); err != nil || !bytes.Equal(b, data) {
+		t.Fatalf("content mismatch: %v", err)
+	}
+	if _, err := fs.Stat(dst, "dst"+CheckpointSuffix); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("checkpoint left: %v", err)
+	}
+}
+
+func TestCopyResumableFSStaleCheckpoint(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 16*1024)
+	src := fstest.MapFS{"src": {Data: data, Mode: 0o640, ModTime: treeModTime}}
+	dst := wfs.NewMem()
+	opts := ResumeOptions{Interval: bufSize}
+
+	if _, err := CopyResumableFS(context.Background(), dst, "dst", failingFS{src, 2 * bufSize}, "src", opts); !errors.Is(err, errInjected) {
+		t.Fatal(err)
+	}
+	// the prefix no longer matches the checkpointed checksum.
+	f, err := dst.OpenFile("dst", os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte("x"), 0); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	result, err := CopyResumableFS(context.Background(), dst, "dst", src, "src", opts)
+	if err != nil || result.ResumedFrom != 0 || result.Written != int64(len(data)) {
+		t.Fatalf("result = %+v, err = %v", result, err)
+	}
+	if b, err := fs.ReadFile(dst, "dst"); err != nil || !bytes.Equal(b, data) {
+		t.Fatalf("content mismatch: %v", err)
+	}
+}
+
+func TestCopyResumableFSNotReaderAt(t *testing.T) {
+	src := fstest.MapFS{"dir": {Mode: fs.ModeDir | 0o755}}
+	_, err := CopyResumableFS(context.Background(), wfs.NewMem(), "dst", src, "dir", ResumeOptions{})
+	if !errors.Is(err, errors.ErrUnsupported) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package iocopy
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io-copy-file/atomicfile"
+	"io-copy-file/wfs"
+	"io/fs"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+type TreeOptions struct {
+	// Concurrency limits the number of files copied at once. 8 if zero.
+	Concurrency int
+	// Verify makes CopyTree read back every copied file and compare its SHA-256 to the source.
+	Verify bool
+}
+
+type TreeReport struct {
+	Dirs     int
+	Files    int
+	Symlinks int
+	// Skipped counts entries neither directory, regular file nor symlink, e.g. sockets or devices,
+	// and symlinks if the destination can not create them.
+	Skipped  int
+	Bytes    int64
+	Verified int
+	Elapsed  time.Duration
+}
+
+// CopyTree is CopyTreeFS from os.DirFS(srcDir) into dstDir, which is created if it does not exist.
+// Symlinks already in dstDir are refused if they point outside of it.
+func CopyTree(ctx context.Context, srcDir, dstDir string, opts TreeOptions) (TreeReport, error) {
+	if err := os.MkdirAll(dstDir, 0o700); err != nil {
+		return TreeReport{}, err
+	}
+	dst, err := wfs.OpenOS(dstDir)
+	if err != nil {
+		return TreeReport{}, err
+	}
+	defer dst.Close()
+	return CopyTreeFS(ctx, dst, os.DirFS(srcDir), opts)
+}
+
+// CopyTreeFS copies the whole tree of src into the root of dst.
+// Regular files are copied concurrently through atomicfile, so a failure never leaves half-written files.
+// Modes of files and directories are preserved, and so are mtimes if dst is a wfs.ChtimesFS.
+// Symlinks are read by fs.ReadLink and copied as symlinks if dst is a wfs.SymlinkFS,
+// with their own mtime where Lchtimes is supported.
+//
+// The report is filled up to the point CopyTreeFS stopped even if it returns an error.
+func CopyTreeFS(ctx context.Context, dst wfs.WritableFS, src fs.FS, opts TreeOptions) (TreeReport, error) {
+	start := time.Now()
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+
+	var (
+		mu     sync.Mutex
+		report TreeReport
+		dirs   []fs.FileInfo
+		names  []string
+	)
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(concurrency)
+
+	walkErr := fs.WalkDir(src, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+
+		switch t := d.Type(); {
+		case t.IsDir():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			// Modes are set after all files are written, since the directory might not be writable.
+			if err := mkdir(dst, name); err != nil {
+				return err
+			}
+			dirs = append(dirs, info)
+			names = append(names, name)
+			mu.Lock()
+			report.Dirs++
+			mu.Unlock()
+		case t&fs.ModeSymlink != 0:
+			sfs, ok := dst.(wfs.SymlinkFS)
+			if !ok {
+				mu.Lock()
+				report.Skipped++
+				mu.Unlock()
+				return nil
+			}
+			target, err := fs.ReadLink(src, name)
+			if err != nil {
+				return err
+			}
+			if err := symlink(sfs, target, name); err != nil {
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			err = sfs.Lchtimes(name, time.Time{}, info.ModTime())
+			if err != nil && !errors.Is(err, errors.ErrUnsupported) {
+				return err
+			}
+			mu.Lock()
+			report.Symlinks++
+			mu.Unlock()
+		case t.IsRegular():
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			g.Go(func() error {
+				n, err := copyFile(ctx, dst, src, name, info, opts.Verify)
+				mu.Lock()
+				defer mu.Unlock()
+				report.Bytes += n
+				if err != nil {
+					return err
+				}
+				report.Files++
+				if opts.Verify {
+					report.Verified++
+				}
+				return nil
+			})
+		default:
+			mu.Lock()
+			report.Skipped++
+			mu.Unlock()
+		}
+		return nil
+	})
+	err := g.Wait()
+	if walkErr != nil {
+		err = walkErr
+	}
+	if err == nil {
+		// deepest first, so that setting a mode or mtime does not disturb the parent's.
+		for i := len(dirs) - 1; i >= 0; i-- {
+			if err = setMeta(dst, names[i], dirs[i]); err != nil {
+				break
+			}
+		}
+	}
+	report.Elapsed = time.Since(start)
+	return report, err
+}
+
+// mkdir creates name in fsys unless it already is a directory.
+func mkdir(fsys wfs.WritableFS, name string) error {
+	err := fsys.Mkdir(name, 0o700)
+	if errors.Is(err, fs.ErrExist) {
+		if info, statErr := fs.Stat(fsys, name); statErr == nil && info.IsDir() {
+			return nil
+		}
+	}
+	return err
+}
+
+// symlink creates name as a symlink to target in fsys,
+// replacing a symlink already there, unless it already points to target.
+func symlink(fsys wfs.SymlinkFS, target, name string) error {
+	err := fsys.Symlink(target, name)
+	if !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+	cur, readErr := fs.ReadLink(fsys, name)
+	switch {
+	case readErr != nil:
+		// not a symlink, or fsys can not tell.
+		return err
+	case cur == target:
+		return nil
+	}
+	if err := fsys.Remove(name); err != nil {
+		return err
+	}
+	return fsys.Symlink(target, name)
+}
+
+// setMeta sets the mode and, if fsys supports it, the mtime of name to ones of info.
+func setMeta(fsys wfs.WritableFS, name string, info fs.FileInfo) error {
+	f, err := fsys.OpenFile(name, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	if err := errors.Join(f.Chmod(info.Mode().Perm()), f.Close()); err != nil {
+		return err
+	}
+	return chtimes(fsys, name, info.ModTime())
+}
+
+func chtimes(fsys wfs.WritableFS, name string, mtime time.Time) error {
+	if cfs, ok := fsys.(wfs.ChtimesFS); ok {
+		return cfs.Chtimes(name, time.Time{}, mtime)
+	}
+	return nil
+}
+
+func copyFile(ctx context.Context, dst wfs.WritableFS, src fs.FS, name string, info fs.FileInfo, verify bool) (int64, error) {
+	r, err := src.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	w, err := atomicfile.CreateFS(dst, name, atomicfile.Options{Perm: info.Mode().Perm()})
+	if err != nil {
+		return 0, err
+	}
+	defer w.Abort()
+
+	var (
+		from   io.Reader = r
+		srcSum hash.Hash
+	)
+	if verify {
+		srcSum = sha256.New()
+		from = io.TeeReader(r, srcSum)
+	}
+	n, err := CopyContext(ctx, w, from, Options{})
+	if err != nil {
+		return n, err
+	}
+	if err := w.Commit(); err != nil {
+		return n, err
+	}
+	if err := chtimes(dst, name, info.ModTime()); err != nil {
+		return n, err
+	}
+
+	if verify {
+		dstSum, err := sumFile(dst, name)
+		if err != nil {
+			return n, err
+		}
+		if !slices.Equal(srcSum.Sum(nil), dstSum) {
+			return n, fmt.Errorf("%w: %s", ErrChecksumMismatch, name)
+		}
+	}
+	return n, nil
+}
+
+func sumFile(fsys fs.FS, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,115 @@
+package iocopy
+
+import (
+	"context"
+	"io-copy-file/wfs"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var treeModTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func srcTree() fstest.MapFS {
+	return fstest.MapFS{
+		".":       {Mode: fs.ModeDir | 0o755, ModTime: treeModTime},
+		"a":       {Mode: fs.ModeDir | 0o750, ModTime: treeModTime},
+		"a/b":     {Mode: fs.ModeDir | 0o700, ModTime: treeModTime},
+		"a/foo":   {Data: []byte("foo"), Mode: 0o600, ModTime: treeModTime},
+		"a/b/bar": {Data: []byte("barbar"), Mode: 0o640, ModTime: treeModTime},
+		"a/link":  {Data: []byte("b/bar"), Mode: fs.ModeSymlink | 0o777, ModTime: treeModTime},
+	}
+}
+
+func checkTree(t *testing.T, dst fs.FS, src fstest.MapFS) {
+	t.Helper()
+	for name, want := range src {
+		if want.Mode&fs.ModeSymlink != 0 {
+			continue
+		}
+		info, err := fs.Stat(dst, name)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if info.Mode() != want.Mode || !info.ModTime().Equal(want.ModTime) {
+			t.Errorf("%s: mode = %v, mtime = %v, want %v, %v", name, info.Mode(), info.ModTime(), want.Mode, want.ModTime)
+		}
+		if want.Mode.IsRegular() {
+			if b, err := fs.ReadFile(dst, name); err != nil || string(b) != string(want.Data) {
+				t.Errorf("%s: content = %q, %v", name, b, err)
+			}
+		}
+	}
+}
+
+func TestCopyTreeFSMem(t *testing.T) {
+	src := srcTree()
+	dst := wfs.NewMem()
+	report, err := CopyTreeFS(context.Background(), dst, src, TreeOptions{Verify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the root is counted as a directory. Mem can not hold symlinks.
+	if report.Dirs != 3 || report.Files != 2 || report.Verified != 2 || report.Symlinks != 0 || report.Skipped != 1 || report.Bytes != 9 {
+		t.Fatalf("report = %+v", report)
+	}
+	checkTree(t, dst, src)
+}
+
+func TestCopyTreeFSOS(t *testing.T) {
+	src := srcTree()
+	dst, err := wfs.OpenOS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	report, err := CopyTreeFS(context.Background(), dst, src, TreeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Dirs != 3 || report.Files != 2 || report.Symlinks != 1 || report.Skipped != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+	checkTree(t, dst, src)
+	if target, err := fs.ReadLink(dst, "a/link"); err != nil || target != "b/bar" {
+		t.Fatalf("link = %q, %v", target, err)
+	}
+}
+
+func TestCopyTreeFSRerun(t *testing.T) {
+	dst, err := wfs.OpenOS(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+	src := srcTree()
+	for range 2 {
+		if _, err := CopyTreeFS(context.Background(), dst, src, TreeOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	src["a/foo"] = &fstest.MapFile{Data: []byte("new"), Mode: 0o600, ModTime: treeModTime}
+	src["a/link"] = &fstest.MapFile{Data: []byte("foo"), Mode: fs.ModeSymlink | 0o777, ModTime: treeModTime}
+	if _, err := CopyTreeFS(context.Background(), dst, src, TreeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	checkTree(t, dst, src)
+	if target, err := fs.ReadLink(dst, "a/link"); err != nil || target != "foo" {
+		t.Fatalf("link = %q, %v", target, err)
+	}
+}
+
+func TestCopyTreeFSCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dst := wfs.NewMem()
+	if _, err := CopyTreeFS(ctx, dst, srcTree(), TreeOptions{}); err != context.Canceled {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := fs.Stat(dst, "a/foo"); err == nil {
+		t.Fatal("a/foo copied after cancel")
+	}
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
+	"io-copy-file/atomicfile"
+	"io-copy-file/iocopy"
+	"io-copy-file/wfs"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 )
 
 func main() {
@@ -39,16 +44,111 @@ func main() {
 	}
 	defer func() { fmt.Printf("%#v\n", src.Close()) }()
 
-	dst, err := os.Create(filepath.Join(tmpDir, "dst"))
+	dst, err := atomicfile.Create(filepath.Join(tmpDir, "dst"), atomicfile.Options{})
 	if err != nil {
 		panic(err)
 	}
-	defer func() { fmt.Printf("%#v\n", dst.Close()) }()
+	defer func() { fmt.Printf("%#v\n", dst.Abort()) }()
 
-	_, err = io.Copy(dst, src)
+	_, err = iocopy.CopyContext(ctx, dst, src, iocopy.Options{
+		Progress: func(p iocopy.Progress) {
+			fmt.Printf("progress: written = %d, total = %d\n", p.Written, p.Total)
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+	// progress: written = 18, total = 18
+
+	err = dst.Commit()
 	if err != nil {
 		panic(err)
 	}
 
 	cancel()
+
+	pr, pw, err := os.Pipe()
+	if err != nil {
+		panic(err)
+	}
+	defer pw.Close()
+	defer pr.Close()
+
+	pipeCtx, pipeCancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer pipeCancel()
+	// nothing is written to pw, the read on pr blocks until the deadline is set on ctx cancellation.
+	n, err := iocopy.CopyContext(pipeCtx, io.Discard, pr, iocopy.Options{})
+	fmt.Printf("pipe: written = %d, err = %v\n", n, err)
+	// pipe: written = 0, err = context deadline exceeded
+
+	srcTree := filepath.Join(tmpDir, "tree")
+	for _, dir := range []string{"a", "a/b"} {
+		if err := os.MkdirAll(filepath.Join(srcTree, dir), 0o755); err != nil {
+			panic(err)
+		}
+	}
+	for _, file := range []string{"a/foo", "a/b/bar"} {
+		if err := os.WriteFile(filepath.Join(srcTree, file), []byte(file), 0o600); err != nil {
+			panic(err)
+		}
+	}
+	if err := os.Symlink("b/bar", filepath.Join(srcTree, "a/link")); err != nil {
+		panic(err)
+	}
+	report, err := iocopy.CopyTree(context.Background(), srcTree, filepath.Join(tmpDir, "tree-copy"), iocopy.TreeOptions{Verify: true})
+	fmt.Printf(
+		"tree: dirs = %d, files = %d, symlinks = %d, bytes = %d, verified = %d, err = %v\n",
+		report.Dirs, report.Files, report.Symlinks, report.Bytes, report.Verified, err,
+	)
+	// tree: dirs = 3, files = 2, symlinks = 1, bytes = 12, verified = 2, err = <nil>
+
+	large := filepath.Join(tmpDir, "large")
+	err = os.WriteFile(large, bytes.Repeat([]byte("0123456789abcdef"), 4*1024*1024), fs.ModePerm)
+	if err != nil {
+		panic(err)
+	}
+	opts := iocopy.ResumeOptions{Interval: 1024 * 1024}
+	// interrupted in the middle.
+	resumeCtx, resumeCancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer resumeCancel()
+	result, err := iocopy.CopyResumable(resumeCtx, filepath.Join(tmpDir, "large-copy"), large, opts)
+	fmt.Printf("first: resumed from = %d, written = %d, err = %v\n", result.ResumedFrom, result.Written, err)
+	result, err = iocopy.CopyResumable(context.Background(), filepath.Join(tmpDir, "large-copy"), large, opts)
+	fmt.Printf("second: resumed from = %d, written = %d, err = %v\n", result.ResumedFrom, result.Written, err)
+	/*
+		offsets vary by how far the first run got.
+
+		first: resumed from = 0, written = 7503872, err = context deadline exceeded
+		second: resumed from = 7503872, written = 59604992, err = <nil>
+	*/
+
+	sparse, err := os.Create(filepath.Join(tmpDir, "sparse"))
+	if err != nil {
+		panic(err)
+	}
+	defer sparse.Close()
+	if err := sparse.Truncate(16 * 1024 * 1024); err != nil {
+		panic(err)
+	}
+	if _, err := sparse.WriteAt([]byte("data"), 8*1024*1024); err != nil {
+		panic(err)
+	}
+	sparseCopy, err := os.Create(filepath.Join(tmpDir, "sparse-copy"))
+	if err != nil {
+		panic(err)
+	}
+	defer sparseCopy.Close()
+	copied, err := iocopy.Copy(context.Background(), sparseCopy, sparse)
+	fmt.Printf("sparse: written = %d, mechanism = %s, holes > 0 = %t, err = %v\n", copied.Written, copied.Mechanism, copied.Holes > 0, err)
+	// on linux, depending on the filesystem:
+	// sparse: written = 16777216, mechanism = copy_file_range, holes > 0 = true, err = <nil>
+
+	mem := wfs.NewMem()
+	report, err = iocopy.CopyTreeFS(context.Background(), mem, os.DirFS(srcTree), iocopy.TreeOptions{Verify: true})
+	fmt.Printf(
+		"tree into mem: dirs = %d, files = %d, symlinks = %d, skipped = %d, err = %v\n",
+		report.Dirs, report.Files, report.Symlinks, report.Skipped, err,
+	)
+	// Mem does not support symlinks.
+	// tree into mem: dirs = 3, files = 2, symlinks = 0, skipped = 1, err = <nil>
 }
@@ -0,0 +1,468 @@
+package wfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var _ ChtimesFS = (*Mem)(nil)
+
+var errWriteAtInAppendMode = errors.New("invalid use of WriteAt on file opened with O_APPEND")
+
+// Mem is an in-memory WritableFS.
+// Like files on the OS, opened files keep referring to their contents after being renamed or removed.
+// Permissions are recorded but not enforced, apart from the access mode passed to OpenFile.
+// Access times are not recorded, and symlinks are not supported.
+//
+// The zero Mem is an empty fs which has only the root directory, and is ready to use.
+type Mem struct {
+	mu    sync.Mutex
+	nodes map[string]*memNode
+}
+
+type memNode struct {
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func NewMem() *Mem {
+	return &Mem{}
+}
+
+// lookup returns the node of name. It must be called with m.mu held.
+func (m *Mem) lookup(name string) *memNode {
+	if m.nodes == nil {
+		m.nodes = map[string]*memNode{
+			".": {mode: fs.ModeDir | 0o755, modTime: time.Now()},
+		}
+	}
+	return m.nodes[name]
+}
+
+func (m *Mem) checkParent(name string) error {
+	parent := m.lookup(path.Dir(name))
+	switch {
+	case parent == nil:
+		return fs.ErrNotExist
+	case !parent.mode.IsDir():
+		return ErrNotDir
+	}
+	return nil
+}
+
+func (m *Mem) hasChildren(name string) bool {
+	for k := range m.nodes {
+		if isDescendant(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDescendant(name, dir string) bool {
+	if dir == "." {
+		return name != "."
+	}
+	return strings.HasPrefix(name, dir+"/")
+}
+
+func (m *Mem) Open(name string) (fs.File, error) {
+	return m.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (m *Mem) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	if err := checkPath("open", name); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := m.lookup(name)
+	switch {
+	case n == nil:
+		if flag&os.O_CREATE == 0 {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		if err := m.checkParent(name); err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+		}
+		n = &memNode{mode: perm.Perm(), modTime: time.Now()}
+		m.nodes[name] = n
+	case flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+	case n.mode.IsDir() && writable(flag):
+		return nil, &fs.PathError{Op: "open", Path: name, Err: ErrIsDir}
+	case flag&os.O_TRUNC != 0 && writable(flag):
+		n.data = nil
+		n.modTime = time.Now()
+	}
+	return &memFile{m: m, name: name, node: n, flag: flag}, nil
+}
+
+func (m *Mem) Mkdir(name string, perm fs.FileMode) error {
+	if err := checkPath("mkdir", name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.lookup(name) != nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	}
+	if err := m.checkParent(name); err != nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: err}
+	}
+	m.nodes[name] = &memNode{mode: fs.ModeDir | perm.Perm(), modTime: time.Now()}
+	return nil
+}
+
+func (m *Mem) Remove(name string) error {
+	if err := checkPath("remove", name); err != nil {
+		return err
+	}
+	if name == "." {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := m.lookup(name)
+	switch {
+	case n == nil:
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	case n.mode.IsDir() && m.hasChildren(name):
+		return &fs.PathError{Op: "remove", Path: name, Err: ErrNotEmpty}
+	}
+	delete(m.nodes, name)
+	return nil
+}
+
+func (m *Mem) Rename(oldname, newname string) error {
+	if err := checkPath("rename", oldname); err != nil {
+		return err
+	}
+	if err := checkPath("rename", newname); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.rename(oldname, newname); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+	}
+	return nil
+}
+
+func (m *Mem) rename(oldname, newname string) error {
+	if oldname == "." || newname == "." {
+		return fs.ErrInvalid
+	}
+	o := m.lookup(oldname)
+	if o == nil {
+		return fs.ErrNotExist
+	}
+	if oldname == newname {
+		return nil
+	}
+	if err := m.checkParent(newname); err != nil {
+		return err
+	}
+	if o.mode.IsDir() && isDescendant(newname, oldname) {
+		return fs.ErrInvalid
+	}
+	if n := m.lookup(newname); n != nil {
+		switch {
+		case n.mode.IsDir() && !o.mode.IsDir():
+			return ErrIsDir
+		case !n.mode.IsDir() && o.mode.IsDir():
+			return ErrNotDir
+		case n.mode.IsDir() && m.hasChildren(newname):
+			return ErrNotEmpty
+		}
+	}
+
+	if o.mode.IsDir() {
+		var moved []string
+		for k := range m.nodes {
+			if isDescendant(k, oldname) {
+				moved = append(moved, k)
+			}
+		}
+		for _, k := range moved {
+			m.nodes[newname+k[len(oldname):]] = m.nodes[k]
+			delete(m.nodes, k)
+		}
+	}
+	m.nodes[newname] = o
+	delete(m.nodes, oldname)
+	return nil
+}
+
+func (m *Mem) Chtimes(name string, atime, mtime time.Time) error {
+	if err := checkPath("chtimes", name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := m.lookup(name)
+	if n == nil {
+		return &fs.PathError{Op: "chtimes", Path: name, Err: fs.ErrNotExist}
+	}
+	if !mtime.IsZero() {
+		n.modTime = mtime
+	}
+	return nil
+}
+
+func writable(flag int) bool {
+	return flag&(os.O_WRONLY|os.O_RDWR) != 0
+}
+
+func readable(flag int) bool {
+	return flag&os.O_WRONLY == 0
+}
+
+type memFile struct {
+	m      *Mem
+	name   string
+	node   *memNode
+	flag   int
+	off    int64
+	closed bool
+	// dirents is entries left to be read by ReadDir. nil until ReadDir is called first.
+	dirents []fs.DirEntry
+}
+
+// check returns an error if f can not be read or written. It must be called with f.m.mu held.
+func (f *memFile) check(op string, write bool) error {
+	var err error
+	switch {
+	case f.closed:
+		err = fs.ErrClosed
+	case write && !writable(f.flag), !write && !readable(f.flag):
+		err = fs.ErrPermission
+	case !write && f.node.mode.IsDir():
+		// directories are never opened for writing.
+		err = ErrIsDir
+	default:
+		return nil
+	}
+	return &fs.PathError{Op: op, Path: f.name, Err: err}
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if f.closed {
+		return nil, &fs.PathError{Op: "stat", Path: f.name, Err: fs.ErrClosed}
+	}
+	return f.node.info(path.Base(f.name)), nil
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if err := f.check("read", false); err != nil {
+		return 0, err
+	}
+	if f.off >= int64(len(f.node.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.node.data[f.off:])
+	f.off += int64(n)
+	return n, nil
+}
+
+func (f *memFile) ReadAt(p []byte, off int64) (int, error) {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if err := f.check("read", false); err != nil {
+		return 0, err
+	}
+	if off < 0 {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if off >= int64(len(f.node.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.node.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if err := f.check("write", true); err != nil {
+		return 0, err
+	}
+	if f.flag&os.O_APPEND != 0 {
+		f.off = int64(len(f.node.data))
+	}
+	f.node.writeAt(p, f.off)
+	f.off += int64(len(p))
+	return len(p), nil
+}
+
+func (f *memFile) WriteAt(p []byte, off int64) (int, error) {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if err := f.check("write", true); err != nil {
+		return 0, err
+	}
+	if f.flag&os.O_APPEND != 0 {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: errWriteAtInAppendMode}
+	}
+	if off < 0 {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrInvalid}
+	}
+	f.node.writeAt(p, off)
+	return len(p), nil
+}
+
+func (n *memNode) writeAt(p []byte, off int64) {
+	size := int64(len(n.data))
+	if end := off + int64(len(p)); end > size {
+		n.data = slices.Grow(n.data, int(end-size))[:end]
+		if off > size {
+			clear(n.data[size:off])
+		}
+	}
+	copy(n.data[off:], p)
+	n.modTime = time.Now()
+}
+
+func (f *memFile) Truncate(size int64) error {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if err := f.check("truncate", true); err != nil {
+		return err
+	}
+	if size < 0 {
+		return &fs.PathError{Op: "truncate", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if cur := int64(len(f.node.data)); size > cur {
+		f.node.data = slices.Grow(f.node.data, int(size-cur))[:size]
+		clear(f.node.data[cur:])
+	} else {
+		f.node.data = f.node.data[:size]
+	}
+	f.node.modTime = time.Now()
+	return nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if f.closed {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrClosed}
+	}
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.off
+	case io.SeekEnd:
+		offset += int64(len(f.node.data))
+	}
+	if offset < 0 || whence < io.SeekStart || whence > io.SeekEnd {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+	f.off = offset
+	return offset, nil
+}
+
+// Sync is a no-op apart from checking that f is open.
+func (f *memFile) Sync() error {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if f.closed {
+		return &fs.PathError{Op: "sync", Path: f.name, Err: fs.ErrClosed}
+	}
+	return nil
+}
+
+func (f *memFile) Chmod(mode fs.FileMode) error {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if f.closed {
+		return &fs.PathError{Op: "chmod", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.node.mode = f.node.mode&^fs.ModePerm | mode.Perm()
+	return nil
+}
+
+func (f *memFile) Close() error {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	return nil
+}
+
+func (f *memFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	f.m.mu.Lock()
+	defer f.m.mu.Unlock()
+	if f.closed {
+		return nil, &fs.PathError{Op: "readdir", Path: f.name, Err: fs.ErrClosed}
+	}
+	if !f.node.mode.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: f.name, Err: ErrNotDir}
+	}
+	if f.dirents == nil {
+		f.dirents = []fs.DirEntry{}
+		for k, node := range f.m.nodes {
+			if k != "." && path.Dir(k) == f.name {
+				f.dirents = append(f.dirents, fs.FileInfoToDirEntry(node.info(path.Base(k))))
+			}
+		}
+		slices.SortFunc(f.dirents, func(i, j fs.DirEntry) int { return strings.Compare(i.Name(), j.Name()) })
+	}
+
+	if n <= 0 {
+		entries := f.dirents
+		f.dirents = f.dirents[len(f.dirents):]
+		return entries, nil
+	}
+	if len(f.dirents) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(f.dirents))
+	entries := f.dirents[:n:n]
+	f.dirents = f.dirents[n:]
+	return entries, nil
+}
+
+func (n *memNode) info(name string) fs.FileInfo {
+	return &fileInfo{
+		name:    name,
+		size:    int64(len(n.data)),
+		mode:    n.mode,
+		modTime: n.modTime,
+	}
+}
+
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (i *fileInfo) Name() string       { return i.name }
+func (i *fileInfo) Size() int64        { return i.size }
+func (i *fileInfo) Mode() fs.FileMode  { return i.mode }
+func (i *fileInfo) ModTime() time.Time { return i.modTime }
+func (i *fileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i *fileInfo) Sys() any           { return nil }
@@ -0,0 +1,122 @@
+package wfs
+
+import (
+	"io/fs"
+	"os"
+	"time"
+)
+
+var (
+	_ ChtimesFS     = (*OS)(nil)
+	_ SymlinkFS     = (*OS)(nil)
+	_ fs.ReadLinkFS = (*OS)(nil)
+)
+
+// OS is a WritableFS rooted at a directory of the OS.
+// Names are refused if they, or symlinks they traverse, point outside of the directory.
+type OS struct {
+	root *os.Root
+}
+
+// OpenOS opens dir as a root of OS.
+// The returned OS must be closed after use.
+func OpenOS(dir string) (*OS, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &OS{root: root}, nil
+}
+
+// Name returns the name of the directory passed to OpenOS.
+func (o *OS) Name() string {
+	return o.root.Name()
+}
+
+func (o *OS) Close() error {
+	return o.root.Close()
+}
+
+func (o *OS) Open(name string) (fs.File, error) {
+	if err := checkPath("open", name); err != nil {
+		return nil, err
+	}
+	f, err := o.root.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (o *OS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	if err := checkPath("open", name); err != nil {
+		return nil, err
+	}
+	f, err := o.root.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (o *OS) Mkdir(name string, perm fs.FileMode) error {
+	if err := checkPath("mkdir", name); err != nil {
+		return err
+	}
+	return o.root.Mkdir(name, perm)
+}
+
+func (o *OS) Remove(name string) error {
+	if err := checkPath("remove", name); err != nil {
+		return err
+	}
+	return o.root.Remove(name)
+}
+
+func (o *OS) Rename(oldname, newname string) error {
+	if err := checkPath("rename", oldname); err != nil {
+		return err
+	}
+	if err := checkPath("rename", newname); err != nil {
+		return err
+	}
+	return o.root.Rename(oldname, newname)
+}
+
+func (o *OS) Chtimes(name string, atime, mtime time.Time) error {
+	if err := checkPath("chtimes", name); err != nil {
+		return err
+	}
+	return o.root.Chtimes(name, atime, mtime)
+}
+
+// Symlink creates newname as a symlink to oldname.
+// oldname is not checked, but later operations through the link are refused if it points outside of the root.
+func (o *OS) Symlink(oldname, newname string) error {
+	if err := checkPath("symlink", newname); err != nil {
+		return err
+	}
+	return o.root.Symlink(oldname, newname)
+}
+
+// Lchtimes is only supported on linux.
+func (o *OS) Lchtimes(name string, atime, mtime time.Time) error {
+	if err := checkPath("lchtimes", name); err != nil {
+		return err
+	}
+	return o.lchtimes(name, atime, mtime)
+}
+
+func (o *OS) ReadLink(name string) (string, error) {
+	if err := checkPath("readlink", name); err != nil {
+		return "", err
+	}
+	return o.root.Readlink(name)
+}
+
+func (o *OS) Lstat(name string) (fs.FileInfo, error) {
+	if err := checkPath("lstat", name); err != nil {
+		return nil, err
+	}
+	return o.root.Lstat(name)
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package wfs
+
+import (
+	"errors"
+	"io/fs"
+	"time"
+)
+
+func (o *OS) lchtimes(name string, atime, mtime time.Time) error {
+	return &fs.PathError{Op: "lchtimes", Path: name, Err: errors.ErrUnsupported}
+}
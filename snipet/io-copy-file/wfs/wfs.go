@@ -0,0 +1,68 @@
+// Package wfs defines a writable extension of fs.FS,
+// with an implementation rooted at an OS directory and an in-memory one.
+//
+// As with fs.FS, names are unrooted, slash-separated paths accepted by fs.ValidPath.
+package wfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"time"
+)
+
+var (
+	ErrIsDir    = errors.New("is a directory")
+	ErrNotDir   = errors.New("not a directory")
+	ErrNotEmpty = errors.New("directory not empty")
+)
+
+// WritableFS is a fs.FS which can also create, modify and remove files.
+type WritableFS interface {
+	fs.FS
+	// OpenFile opens the named file with os.O_* flags.
+	// perm is used only when the file is created.
+	OpenFile(name string, flag int, perm fs.FileMode) (File, error)
+	Mkdir(name string, perm fs.FileMode) error
+	// Remove removes the named file or empty directory.
+	Remove(name string) error
+	// Rename renames oldname to newname, replacing newname if it already exists and is not a directory.
+	Rename(oldname, newname string) error
+}
+
+// File is a file opened by WritableFS.OpenFile.
+// *os.File implements it.
+type File interface {
+	fs.File
+	io.Reader
+	io.ReaderAt
+	io.Writer
+	io.WriterAt
+	io.Seeker
+	Sync() error
+	Chmod(mode fs.FileMode) error
+	Truncate(size int64) error
+}
+
+// ChtimesFS is a WritableFS which can change access and modification times of files.
+// A zero time.Time leaves the time unchanged, as os.Chtimes does.
+type ChtimesFS interface {
+	WritableFS
+	Chtimes(name string, atime, mtime time.Time) error
+}
+
+// SymlinkFS is a WritableFS which can create symbolic links.
+type SymlinkFS interface {
+	WritableFS
+	Symlink(oldname, newname string) error
+	// Lchtimes is like Chtimes of ChtimesFS but does not follow name if it is a symlink.
+	// It may return errors.ErrUnsupported.
+	Lchtimes(name string, atime, mtime time.Time) error
+}
+
+func checkPath(op, name string) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return nil
+}
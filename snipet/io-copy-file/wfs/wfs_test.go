@@ -0,0 +1,179 @@
+package wfs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func openOS(t *testing.T) (*OS, string) {
+	t.Helper()
+	dir := t.TempDir()
+	fsys, err := OpenOS(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = fsys.Close() })
+	return fsys, dir
+}
+
+func testFS(t *testing.T) map[string]WritableFS {
+	osFS, _ := openOS(t)
+	return map[string]WritableFS{"Mem": NewMem(), "OS": osFS}
+}
+
+func writeFile(t *testing.T, fsys WritableFS, name, content string) {
+	t.Helper()
+	f, err := fsys.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.WriteString(f, content); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFS(t *testing.T) {
+	for name, fsys := range testFS(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := fsys.Mkdir("dir", 0o755); err != nil {
+				t.Fatal(err)
+			}
+			writeFile(t, fsys, "dir/foo", "foo")
+			writeFile(t, fsys, "dir/bar", "bar")
+			if err := fsys.Rename("dir/bar", "dir/baz"); err != nil {
+				t.Fatal(err)
+			}
+			if err := fstest.TestFS(fsys, "dir/foo", "dir/baz"); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestRemoveNotEmpty(t *testing.T) {
+	for name, fsys := range testFS(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := fsys.Mkdir("dir", 0o755); err != nil {
+				t.Fatal(err)
+			}
+			writeFile(t, fsys, "dir/foo", "foo")
+			err := fsys.Remove("dir")
+			if !errors.Is(err, ErrNotEmpty) && !errors.Is(err, syscall.ENOTEMPTY) {
+				t.Fatalf("err = %v", err)
+			}
+			if err := fsys.Remove("dir/foo"); err != nil {
+				t.Fatal(err)
+			}
+			if err := fsys.Remove("dir"); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestTruncateAndChtimes(t *testing.T) {
+	for name, fsys := range testFS(t) {
+		t.Run(name, func(t *testing.T) {
+			writeFile(t, fsys, "foo", "foobar")
+			f, err := fsys.OpenFile("foo", os.O_RDWR, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if err := f.Truncate(3); err != nil {
+				t.Fatal(err)
+			}
+			if err := f.Truncate(5); err != nil {
+				t.Fatal(err)
+			}
+			b, err := fs.ReadFile(fsys, "foo")
+			if err != nil || string(b) != "foo\x00\x00" {
+				t.Fatalf("content = %q, %v", b, err)
+			}
+
+			mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+			if err := fsys.(ChtimesFS).Chtimes("foo", time.Time{}, mtime); err != nil {
+				t.Fatal(err)
+			}
+			info, err := fs.Stat(fsys, "foo")
+			if err != nil || !info.ModTime().Equal(mtime) {
+				t.Fatalf("mtime = %v, %v", info.ModTime(), err)
+			}
+		})
+	}
+}
+
+func TestMemOpenedFileOutlivesRename(t *testing.T) {
+	fsys := NewMem()
+	writeFile(t, fsys, "foo", "foo")
+	f, err := fsys.OpenFile("foo", os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := fsys.Rename("foo", "bar"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt([]byte("baz"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := fs.ReadFile(fsys, "bar"); err != nil || string(b) != "baz" {
+		t.Fatalf("content = %q, %v", b, err)
+	}
+
+	if _, err := fsys.OpenFile("foo", os.O_RDONLY, 0); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := fsys.OpenFile(".", os.O_RDWR, 0); !errors.Is(err, ErrIsDir) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestOSRefusesEscape(t *testing.T) {
+	fsys, dir := openOS(t)
+	if err := os.Symlink(t.TempDir(), filepath.Join(dir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"../foo", "/foo", "escape/foo"} {
+		if _, err := fsys.OpenFile(name, os.O_RDWR|os.O_CREATE, 0o644); err == nil {
+			t.Errorf("%q: opened", name)
+		}
+	}
+}
+
+func TestOSSymlink(t *testing.T) {
+	fsys, _ := openOS(t)
+	writeFile(t, fsys, "foo", "foo")
+	if err := fsys.Symlink("foo", "link"); err != nil {
+		t.Fatal(err)
+	}
+	if target, err := fs.ReadLink(fsys, "link"); err != nil || target != "foo" {
+		t.Fatalf("target = %q, %v", target, err)
+	}
+
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := fsys.Lchtimes("link", time.Time{}, mtime)
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := fs.Lstat(fsys, "link")
+	if err != nil || !info.ModTime().Equal(mtime) {
+		t.Fatalf("link mtime = %v, %v", info.ModTime(), err)
+	}
+	if info, _ := fs.Stat(fsys, "foo"); info.ModTime().Equal(mtime) {
+		t.Fatal("target mtime changed")
+	}
+}
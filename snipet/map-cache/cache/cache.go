@@ -0,0 +1,304 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Options struct {
+	// NegativeTTL is how long an error returned from the loader is cached.
+	// If zero, errors are not cached: the error is shared only by callers waiting on the same load,
+	// and the next Get loads again.
+	// Expired errors are swept whenever a load completes, so they do not pile up for keys never requested again.
+	NegativeTTL time.Duration
+}
+
+// Cache caches values loaded by a loader function.
+// Concurrent Gets for a key being loaded wait for the single in-flight load instead of loading again.
+//
+// Hits are served by sync.Map.Load and atomic operations without taking any lock.
+// On a bounded cache, a hit takes a mutex only if the value has drifted out of
+// the most recently used quarter of held values, to move it to the front.
+// Frequently hit values therefore stay lock-free, at the cost of the order being approximate within that quarter.
+type Cache[K comparable, V any] struct {
+	m    sync.Map // K -> *entry[K, V]
+	load func(key K) (V, error)
+	opts Options
+
+	maxCost int64
+	cost    func(v V) int64
+
+	// mu guards admission, removal and reordering of loaded values.
+	mu sync.Mutex
+	// lru holds loaded values, most recently used first.
+	lru   *list.List
+	total int64
+	// tick is incremented each time a value moves to the front of lru, and only while holding mu.
+	tick atomic.Uint64
+	// held mirrors lru.Len() for hits, which do not hold mu.
+	held atomic.Int64
+	// negatives holds errors cached for Options.NegativeTTL, oldest first.
+	// Since the TTL is the same for all, it is also the order of expiry.
+	negatives *list.List
+
+	hits, misses, evictions atomic.Uint64
+}
+
+type entry[K comparable, V any] struct {
+	key    K
+	loaded atomic.Bool
+	done   chan struct{}
+	v      V
+	err    error
+	// expires is set only for errors cached for Options.NegativeTTL.
+	expires time.Time
+
+	// elem, negElem and cost are guarded by Cache.mu.
+	// elem is nil unless the entry is in lru, and negElem is nil unless it is in negatives.
+	elem    *list.Element
+	negElem *list.Element
+	cost    int64
+	// promoted is Cache.tick when the entry last moved to the front of lru.
+	// At most tick - promoted values are ahead of it.
+	promoted atomic.Uint64
+}
+
+// Stats is a snapshot of counters of a Cache.
+type Stats struct {
+	// Hits counts Gets served without calling the loader, including ones waited on an in-flight load.
+	Hits uint64
+	// Misses counts Gets which called the loader.
+	Misses    uint64
+	Evictions uint64
+	// Entries and Cost are the number and the total cost of loaded values held.
+	Entries int
+	Cost    int64
+	// Negatives is the number of errors cached for Options.NegativeTTL,
+	// including expired ones not swept yet. They are not counted in Entries and Cost.
+	Negatives int
+}
+
+// New returns an unbounded Cache.
+func New[K comparable, V any](load func(key K) (V, error), opts Options) *Cache[K, V] {
+	return NewBounded(load, 0, nil, opts)
+}
+
+// NewBounded returns a Cache evicting values once the sum of cost of held values exceeds maxCost.
+// If maxCost is zero, the cache is unbounded. A nil cost counts every value as 1.
+//
+// The least recently used values are evicted first, with the order approximated as described in Cache.
+// A value costing more than maxCost is returned from Get but not held, and evicts nothing.
+func NewBounded[K comparable, V any](load func(key K) (V, error), maxCost int64, cost func(v V) int64, opts Options) *Cache[K, V] {
+	if cost == nil {
+		cost = func(V) int64 { return 1 }
+	}
+	return &Cache[K, V]{
+		load:      load,
+		opts:      opts,
+		maxCost:   maxCost,
+		cost:      cost,
+		lru:       list.New(),
+		negatives: list.New(),
+	}
+}
+
+// Get returns the value for key, loading it if it is not cached yet.
+func (c *Cache[K, V]) Get(key K) (V, error) {
+	for {
+		if v, ok := c.m.Load(key); ok {
+			e := v.(*entry[K, V])
+			if !e.loaded.Load() {
+				<-e.done
+			} else if !e.expires.IsZero() && !time.Now().Before(e.expires) {
+				c.expire(e)
+				continue
+			}
+			if c.maxCost > 0 && e.err == nil && c.drifted(e) {
+				c.touch(e)
+			}
+			c.hits.Add(1)
+			return e.v, e.err
+		}
+
+		e := &entry[K, V]{key: key, done: make(chan struct{})}
+		if _, loaded := c.m.LoadOrStore(key, e); loaded {
+			continue
+		}
+		c.misses.Add(1)
+		c.fill(key, e)
+		return e.v, e.err
+	}
+}
+
+func (c *Cache[K, V]) fill(key K, e *entry[K, V]) {
+	var normalReturn bool
+	defer func() {
+		if !normalReturn {
+			// let waiters return instead of blocking forever,
+			// then propagate the panic (or runtime.Goexit) to the loading caller.
+			rec := recover()
+			e.err = fmt.Errorf("cache: loader did not return: %v", rec)
+			c.m.CompareAndDelete(key, e)
+			e.loaded.Store(true)
+			close(e.done)
+			if rec != nil {
+				panic(rec)
+			}
+		}
+	}()
+
+	e.v, e.err = c.load(key)
+	normalReturn = true
+
+	switch {
+	case e.err == nil:
+		c.admit(e, max(c.cost(e.v), 0))
+	case c.opts.NegativeTTL > 0:
+		e.expires = time.Now().Add(c.opts.NegativeTTL)
+		c.admitNegative(e)
+	default:
+		c.m.CompareAndDelete(key, e)
+	}
+	e.loaded.Store(true)
+	close(e.done)
+}
+
+// admit puts e at the front of lru, then evicts values from the back until the total cost fits in maxCost.
+func (c *Cache[K, V]) admit(e *entry[K, V], cost int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sweep()
+	if cur, ok := c.m.Load(e.key); !ok || cur != e {
+		// invalidated while loading.
+		return
+	}
+	if c.maxCost > 0 && cost > c.maxCost {
+		// holding it would evict everything else and still not fit.
+		c.m.CompareAndDelete(e.key, e)
+		return
+	}
+	e.cost = cost
+	e.elem = c.lru.PushFront(e)
+	e.promoted.Store(c.tick.Add(1))
+	c.total += cost
+
+	for c.maxCost > 0 && c.total > c.maxCost {
+		victim := c.lru.Back().Value.(*entry[K, V])
+		c.remove(victim)
+		c.m.CompareAndDelete(victim.key, victim)
+		c.evictions.Add(1)
+	}
+	c.held.Store(int64(c.lru.Len()))
+}
+
+// drifted reports whether e may be out of the most recently used quarter of lru.
+func (c *Cache[K, V]) drifted(e *entry[K, V]) bool {
+	return c.tick.Load()-e.promoted.Load() > uint64(c.held.Load())/4
+}
+
+// touch moves e to the front of lru if it is still held.
+func (c *Cache[K, V]) touch(e *entry[K, V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e.elem != nil {
+		c.lru.MoveToFront(e.elem)
+		e.promoted.Store(c.tick.Add(1))
+	}
+}
+
+// admitNegative puts e, holding an error, into negatives.
+func (c *Cache[K, V]) admitNegative(e *entry[K, V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sweep()
+	if cur, ok := c.m.Load(e.key); !ok || cur != e {
+		return
+	}
+	e.negElem = c.negatives.PushBack(e)
+}
+
+// sweep removes expired errors. It must be called with c.mu held.
+func (c *Cache[K, V]) sweep() {
+	if c.negatives.Len() == 0 {
+		return
+	}
+	now := time.Now()
+	for el := c.negatives.Front(); el != nil; el = c.negatives.Front() {
+		e := el.Value.(*entry[K, V])
+		if now.Before(e.expires) {
+			return
+		}
+		c.remove(e)
+		c.m.CompareAndDelete(e.key, e)
+	}
+}
+
+// expire removes e, an expired error found by Get.
+func (c *Cache[K, V]) expire(e *entry[K, V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(e)
+	c.m.CompareAndDelete(e.key, e)
+}
+
+// remove removes e from lru or negatives. It must be called with c.mu held.
+func (c *Cache[K, V]) remove(e *entry[K, V]) {
+	if e.negElem != nil {
+		c.negatives.Remove(e.negElem)
+		e.negElem = nil
+	}
+	if e.elem == nil {
+		return
+	}
+	c.lru.Remove(e.elem)
+	e.elem = nil
+	c.total -= e.cost
+	c.held.Store(int64(c.lru.Len()))
+}
+
+// Invalidate removes key from c so that the next Get loads it again.
+// Callers already waiting on an in-flight load of key still receive its result,
+// but the result is not cached.
+func (c *Cache[K, V]) Invalidate(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := c.m.LoadAndDelete(key); ok {
+		c.remove(v.(*entry[K, V]))
+	}
+}
+
+// Purge removes every key from c. See Invalidate.
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m.Clear()
+	for el := c.lru.Front(); el != nil; el = el.Next() {
+		el.Value.(*entry[K, V]).elem = nil
+	}
+	c.lru.Init()
+	c.total = 0
+	c.held.Store(0)
+	for el := c.negatives.Front(); el != nil; el = el.Next() {
+		el.Value.(*entry[K, V]).negElem = nil
+	}
+	c.negatives.Init()
+}
+
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	entries, cost, negatives := c.lru.Len(), c.total, c.negatives.Len()
+	c.mu.Unlock()
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Entries:   entries,
+		Cost:      cost,
+		Negatives: negatives,
+	}
+}
@@ -0,0 +1,295 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newCounting returns a bounded Cache of values equal to their keys, and a map counting loads per key.
+func newCounting(maxCost int64, cost func(v int) int64) (*Cache[int, int], map[int]int) {
+	loads := map[int]int{}
+	c := NewBounded(func(key int) (int, error) {
+		loads[key]++
+		return key, nil
+	}, maxCost, cost, Options{})
+	return c, loads
+}
+
+func costKey(v int) int64 { return int64(v) }
+
+func TestLRUOrder(t *testing.T) {
+	c, loads := newCounting(3, nil)
+	for _, key := range []int{1, 2, 3, 3, 2, 1} {
+		_, _ = c.Get(key)
+	}
+	// 3 is the least recently used, even though 4 was never hit.
+	_, _ = c.Get(4)
+	if s := c.Stats(); s.Evictions != 1 || s.Entries != 3 || s.Cost != 3 {
+		t.Fatalf("stats = %+v", s)
+	}
+	for _, key := range []int{4, 1, 2, 3} {
+		_, _ = c.Get(key)
+	}
+	if loads[1] != 1 || loads[2] != 1 || loads[3] != 2 || loads[4] != 1 {
+		t.Fatalf("loads = %v", loads)
+	}
+}
+
+func TestHotHitsDoNotLock(t *testing.T) {
+	c, _ := newCounting(8, nil)
+	for key := range 8 {
+		_, _ = c.Get(key)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// 7 and 6 are in the most recently used quarter.
+		for range 100 {
+			_, _ = c.Get(7)
+			_, _ = c.Get(6)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("hits blocked on the lock")
+	}
+}
+
+func TestOversized(t *testing.T) {
+	c, loads := newCounting(4, costKey)
+	_, _ = c.Get(1)
+	_, _ = c.Get(3)
+	v, err := c.Get(5)
+	if v != 5 || err != nil {
+		t.Fatalf("Get = %d, %v", v, err)
+	}
+	if s := c.Stats(); s.Evictions != 0 || s.Entries != 2 || s.Cost != 4 {
+		t.Fatalf("stats = %+v", s)
+	}
+	_, _ = c.Get(5)
+	if loads[5] != 2 {
+		t.Fatalf("oversized value held: loads = %v", loads)
+	}
+}
+
+func TestUnbounded(t *testing.T) {
+	c, loads := newCounting(0, nil)
+	for key := range 100 {
+		_, _ = c.Get(key)
+		_, _ = c.Get(key)
+	}
+	if s := c.Stats(); s.Hits != 100 || s.Misses != 100 || s.Evictions != 0 || s.Entries != 100 {
+		t.Fatalf("stats = %+v", s)
+	}
+	if len(loads) != 100 {
+		t.Fatalf("loads = %v", loads)
+	}
+}
+
+// gate is a loader blocking until released, to keep a load in flight.
+type gate struct {
+	started chan struct{}
+	release chan struct{}
+	loads   atomic.Int64
+	// onRelease, if non-nil, is called by the first load instead of returning.
+	onRelease func()
+}
+
+func newGate() *gate {
+	return &gate{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gate) load(key string) (string, error) {
+	if g.loads.Add(1) == 1 {
+		close(g.started)
+		<-g.release
+		if g.onRelease != nil {
+			g.onRelease()
+		}
+	}
+	return key, nil
+}
+
+// inFlight gives goroutines started after the load time to block on it.
+// Tests pass either way, but only exercise waiting if they did.
+func inFlight() {
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestInFlightDedup(t *testing.T) {
+	g := newGate()
+	c := New(g.load, Options{})
+
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := c.Get("foo"); v != "foo" || err != nil {
+				errs <- fmt.Errorf("Get = %q, %v", v, err)
+			}
+		}()
+	}
+	<-g.started
+	inFlight()
+	close(g.release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if g.loads.Load() != 1 {
+		t.Fatalf("loads = %d", g.loads.Load())
+	}
+	if s := c.Stats(); s.Hits != n-1 || s.Misses != 1 || s.Entries != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestNegativeTTL(t *testing.T) {
+	const ttl = 20 * time.Millisecond
+	var loads atomic.Int64
+	c := New(func(key string) (string, error) {
+		loads.Add(1)
+		return "", errors.New(key)
+	}, Options{NegativeTTL: ttl})
+
+	if _, err := c.Get("foo"); err == nil || err.Error() != "foo" {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := c.Get("foo"); err == nil || loads.Load() != 1 {
+		t.Fatalf("err = %v, loads = %d", err, loads.Load())
+	}
+	if s := c.Stats(); s.Negatives != 1 || s.Entries != 0 {
+		t.Fatalf("stats = %+v", s)
+	}
+	time.Sleep(ttl)
+	if _, _ = c.Get("foo"); loads.Load() != 2 {
+		t.Fatalf("loads after ttl = %d", loads.Load())
+	}
+
+	// keys never requested again are swept by later loads.
+	for i := range 100 {
+		_, _ = c.Get(fmt.Sprint(i))
+	}
+	time.Sleep(ttl)
+	_, _ = c.Get("bar")
+	if s := c.Stats(); s.Negatives != 1 {
+		t.Fatalf("stats = %+v", s)
+	}
+	var held int
+	c.m.Range(func(any, any) bool {
+		held++
+		return true
+	})
+	if held != 1 {
+		t.Fatalf("held = %d", held)
+	}
+}
+
+func TestErrorNotCachedWithoutTTL(t *testing.T) {
+	var loads atomic.Int64
+	c := New(func(key string) (string, error) {
+		loads.Add(1)
+		return "", errors.New(key)
+	}, Options{})
+	_, _ = c.Get("foo")
+	_, _ = c.Get("foo")
+	if loads.Load() != 2 || c.Stats().Negatives != 0 {
+		t.Fatalf("loads = %d, stats = %+v", loads.Load(), c.Stats())
+	}
+}
+
+func TestInvalidateDuringLoad(t *testing.T) {
+	g := newGate()
+	c := New(g.load, Options{})
+
+	done := make(chan string)
+	go func() {
+		v, _ := c.Get("foo")
+		done <- v
+	}()
+	<-g.started
+	c.Invalidate("foo")
+	close(g.release)
+	// the caller still receives the result of its load.
+	if v := <-done; v != "foo" {
+		t.Fatalf("v = %q", v)
+	}
+	if s := c.Stats(); s.Entries != 0 {
+		t.Fatalf("stats = %+v", s)
+	}
+	_, _ = c.Get("foo")
+	if g.loads.Load() != 2 {
+		t.Fatalf("loads = %d", g.loads.Load())
+	}
+}
+
+func TestLoaderPanicReleasesWaiters(t *testing.T) {
+	g := newGate()
+	g.onRelease = func() { panic("boom") }
+	c := New(g.load, Options{})
+
+	recovered := make(chan any)
+	go func() {
+		defer func() { recovered <- recover() }()
+		_, _ = c.Get("foo")
+	}()
+	<-g.started
+	waited := make(chan error)
+	go func() {
+		_, err := c.Get("foo")
+		waited <- err
+	}()
+	inFlight()
+	close(g.release)
+
+	if rec := <-recovered; rec != "boom" {
+		t.Fatalf("recovered %v", rec)
+	}
+	if err := <-waited; err == nil || !strings.Contains(err.Error(), "loader did not return") {
+		t.Fatalf("waiter err = %v", err)
+	}
+	// the failed load is not cached.
+	if v, err := c.Get("foo"); v != "foo" || err != nil {
+		t.Fatalf("Get = %q, %v", v, err)
+	}
+}
+
+func TestLoaderGoexitReleasesWaiters(t *testing.T) {
+	g := newGate()
+	g.onRelease = runtime.Goexit
+	c := New(g.load, Options{})
+
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		_, _ = c.Get("foo")
+		t.Error("Get returned after Goexit")
+	}()
+	<-g.started
+	waited := make(chan error)
+	go func() {
+		_, err := c.Get("foo")
+		waited <- err
+	}()
+	inFlight()
+	close(g.release)
+
+	<-exited
+	if err := <-waited; err == nil || !strings.Contains(err.Error(), "loader did not return") {
+		t.Fatalf("waiter err = %v", err)
+	}
+}
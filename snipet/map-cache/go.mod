@@ -0,0 +1,11 @@
+module map-cache
+
+go 1.25.0
+
+require io-copy-file v0.0.0
+
+require golang.org/x/sys v0.22.0 // indirect
+
+replace io-copy-file => ../io-copy-file
+
+replace buf-pool => ../buf-pool
@@ -1,28 +1,141 @@
 package main
 
 import (
+	"fmt"
 	"image"
+	"image/color"
 	"image/png"
+	"io-copy-file/wfs"
+	"io/fs"
+	"map-cache/cache"
 	"os"
-	"path/filepath"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
-var cache sync.Map
+var (
+	// imageFS is where images are loaded from. Swapped for an in-memory fs in main.
+	imageFS fs.FS = os.DirFS("image")
+	loads   atomic.Int64
+	images  = cache.NewBounded(func(name string) (image.Image, error) {
+		loads.Add(1)
+		f, err := imageFS.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return png.Decode(f)
+	}, maxImageBytes, decodedSize, cache.Options{NegativeTTL: time.Minute})
+)
+
+// maxImageBytes caps the memory held by decoded images in images.
+const maxImageBytes = 64 * 64 * 4
+
+// decodedSize estimates bytes held by pixels of img from its bounds and color model.
+func decodedSize(img image.Image) int64 {
+	return int64(img.Bounds().Dx()) * int64(img.Bounds().Dy()) * bytesPerPixel(img.ColorModel())
+}
+
+func bytesPerPixel(m color.Model) int64 {
+	if _, ok := m.(color.Palette); ok {
+		return 1
+	}
+	switch m {
+	case color.AlphaModel, color.GrayModel:
+		return 1
+	case color.Alpha16Model, color.Gray16Model:
+		return 2
+	case color.YCbCrModel:
+		// 4:4:4. subsampled images are smaller.
+		return 3
+	case color.RGBAModel, color.NRGBAModel, color.CMYKModel, color.NYCbCrAModel:
+		return 4
+	default:
+		// RGBA64Model, NRGBA64Model and unknown models.
+		return 8
+	}
+}
 
 func loadImage(name string) (image.Image, error) {
-	v, ok := cache.Load(name)
-	if !ok {
-		v, _ = cache.LoadOrStore(
-			name,
-			sync.OnceValues(func() (image.Image, error) {
-				f, err := os.Open(filepath.Join("image", name))
-				if err != nil {
-					return nil, err
-				}
-				return png.Decode(f)
-			}),
-		)
-	}
-	return v.(func() (image.Image, error))()
+	return images.Get(name)
+}
+
+func main() {
+	mem := wfs.NewMem()
+	imageFS = mem
+	if err := writePng(mem, "foo.png", 2, 2); err != nil {
+		panic(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := loadImage("foo.png"); err != nil {
+				panic(err)
+			}
+		}()
+	}
+	wg.Wait()
+	fmt.Printf("loads = %d\n", loads.Load())
+	// loads = 1
+
+	_, err := loadImage("bar.png")
+	fmt.Printf("missing: %v\n", err)
+	// missing: open bar.png: file does not exist
+	if err := writePng(mem, "bar.png", 4, 4); err != nil {
+		panic(err)
+	}
+	_, err = loadImage("bar.png")
+	fmt.Printf("within negative ttl: %v\n", err)
+	// within negative ttl: open bar.png: file does not exist
+	images.Invalidate("bar.png")
+	img, err := loadImage("bar.png")
+	fmt.Printf("after invalidate: bounds = %v, err = %v\n", img.Bounds(), err)
+	// after invalidate: bounds = (0,0)-(4,4), err = <nil>
+
+	images.Purge()
+	_, _ = loadImage("foo.png")
+	fmt.Printf("loads = %d\n", loads.Load())
+	// loads = 4
+
+	// foo.png is hit after each load, so the least recently used thumbnails are evicted instead.
+	for i := range 4 {
+		name := fmt.Sprintf("thumb%d.png", i)
+		if err := writePng(mem, name, 32, 32); err != nil {
+			panic(err)
+		}
+		if _, err := loadImage(name); err != nil {
+			panic(err)
+		}
+		_, _ = loadImage("foo.png")
+	}
+	fmt.Printf("%+v\n", images.Stats())
+	// {Hits:14 Misses:8 Evictions:1 Entries:4 Cost:12304 Negatives:0}
+	before := loads.Load()
+	_, _ = loadImage("foo.png")
+	_, _ = loadImage("thumb3.png")
+	fmt.Printf("reloaded = %d\n", loads.Load()-before)
+	// reloaded = 0
+
+	// larger than maxImageBytes, so it is returned but neither held nor evicting others.
+	if err := writePng(mem, "large.png", 128, 128); err != nil {
+		panic(err)
+	}
+	img, err = loadImage("large.png")
+	fmt.Printf("large: bounds = %v, err = %v, %+v\n", img.Bounds(), err, images.Stats())
+	// large: bounds = (0,0)-(128,128), err = <nil>, {Hits:16 Misses:9 Evictions:1 Entries:4 Cost:12304 Negatives:0}
+}
+
+func writePng(fsys wfs.WritableFS, name string, w, h int) error {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	img.Set(0, 0, color.White)
+	f, err := fsys.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return png.Encode(f, img)
 }
@@ -0,0 +1,12 @@
+module os-file
+
+go 1.25.0
+
+require (
+	golang.org/x/sys v0.22.0
+	io-copy-file v0.0.0
+)
+
+replace io-copy-file => ../io-copy-file
+
+replace buf-pool => ../buf-pool
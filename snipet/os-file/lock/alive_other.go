@@ -0,0 +1,14 @@
+//go:build !unix
+
+package lock
+
+import "os"
+
+func alive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
@@ -0,0 +1,15 @@
+//go:build unix
+
+package lock
+
+import (
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+func alive(pid int) bool {
+	err := unix.Kill(pid, 0)
+	// EPERM: exists but owned by someone else.
+	return err == nil || errors.Is(err, unix.EPERM)
+}
@@ -0,0 +1,95 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// File is a lock file holding the PID of the process owning it.
+//
+// Ownership is decided by an exclusive flock on the file, not by its existence,
+// so a crashed owner never blocks others: the kernel releases the lock on exit.
+// The PID left by such an owner is reported as StalePID.
+// The file is never removed, since unlinking a locked file lets two processes
+// hold locks on different inodes of the same path.
+type File struct {
+	f *os.File
+	// StalePID is the PID of the previous owner which exited without Release, or zero.
+	StalePID int
+}
+
+// Acquire waits until it owns the lock file at path, or ctx is done.
+func Acquire(ctx context.Context, path string) (*File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := Flock(ctx, f, Exclusive); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	lf := &File{f: f}
+	if pid, err := readPID(f); err == nil && pid != 0 && pid != os.Getpid() && !alive(pid) {
+		lf.StalePID = pid
+	}
+	err = errors.Join(
+		f.Truncate(0),
+		writePID(f, os.Getpid()),
+		f.Sync(),
+	)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return lf, nil
+}
+
+// Release clears the PID and releases the lock.
+func (lf *File) Release() error {
+	return errors.Join(
+		lf.f.Truncate(0),
+		Funlock(lf.f),
+		lf.f.Close(),
+	)
+}
+
+// Holder reads the PID written in the lock file at path, for diagnostics.
+// pid is zero if the file has no owner. running is false if the owner has exited without Release.
+func Holder(path string) (pid int, running bool, err error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	defer f.Close()
+	pid, err = readPID(f)
+	if err != nil || pid == 0 {
+		return 0, false, err
+	}
+	return pid, alive(pid), nil
+}
+
+func readPID(f *os.File) (int, error) {
+	bin, err := io.ReadAll(io.NewSectionReader(f, 0, 32))
+	if err != nil {
+		return 0, err
+	}
+	s := strings.TrimSpace(string(bin))
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func writePID(f *os.File, pid int) error {
+	_, err := f.WriteAt([]byte(strconv.Itoa(pid)+"\n"), 0)
+	return err
+}
@@ -0,0 +1,48 @@
+package lock
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+type Mode int
+
+const (
+	Shared Mode = iota + 1
+	Exclusive
+)
+
+// Flock places an advisory lock on the whole f by flock(2), waiting until it is acquired or ctx is done.
+// flock locks belong to the open file description, so they are shared by dup'ed descriptors
+// and released when every one of them is closed.
+func Flock(ctx context.Context, f *os.File, mode Mode) error {
+	return poll(ctx, func() (bool, error) { return TryFlock(f, mode) })
+}
+
+// OFDLock is like Flock but uses open file description locks of fcntl(2), only available on linux.
+// Unlike classic POSIX record locks, they belong to the open file description like flock locks do,
+// so they are not released when another descriptor of the same file is closed by the process.
+// The file must be opened for writing to take an Exclusive lock.
+func OFDLock(ctx context.Context, f *os.File, mode Mode) error {
+	return poll(ctx, func() (bool, error) { return TryOFDLock(f, mode) })
+}
+
+// poll calls try until it acquires the lock with a backoff, since blocking lock calls can not be interrupted.
+func poll(ctx context.Context, try func() (bool, error)) error {
+	wait := time.Millisecond
+	for {
+		ok, err := try()
+		if err != nil || ok {
+			return err
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return context.Cause(ctx)
+		case <-t.C:
+		}
+		wait = min(wait*2, 100*time.Millisecond)
+	}
+}
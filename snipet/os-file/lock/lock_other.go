@@ -0,0 +1,17 @@
+//go:build !unix || aix
+
+package lock
+
+import (
+	"errors"
+	"os"
+)
+
+// TryFlock is not supported: flock(2) is not available on aix and platforms other than unix.
+func TryFlock(f *os.File, mode Mode) (bool, error) {
+	return false, errors.ErrUnsupported
+}
+
+func Funlock(f *os.File) error {
+	return errors.ErrUnsupported
+}
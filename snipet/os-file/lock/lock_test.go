@@ -0,0 +1,151 @@
+//go:build unix && !aix
+
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const helperEnv = "LOCK_TEST_HELPER"
+
+// TestHelperProcess is not a real test. It is run as a child process by helper
+// to contend for the lock at the path passed after "--".
+func TestHelperProcess(t *testing.T) {
+	mode := os.Getenv(helperEnv)
+	if mode == "" {
+		return
+	}
+	path := os.Args[len(os.Args)-1]
+
+	switch mode {
+	case "acquire", "crash":
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		lf, err := Acquire(ctx, path)
+		if mode == "crash" {
+			// exit without Release.
+			os.Exit(0)
+		}
+		fmt.Print(err)
+		if err == nil {
+			_ = lf.Release()
+		}
+	case "shared", "exclusive":
+		f, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			fmt.Print(err)
+			break
+		}
+		m := Shared
+		if mode == "exclusive" {
+			m = Exclusive
+		}
+		ok, err := TryFlock(f, m)
+		fmt.Print(ok, err)
+	}
+	os.Exit(0)
+}
+
+// helper runs TestHelperProcess in a child process and returns its output and pid.
+func helper(t *testing.T, mode, path string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$", "--", path)
+	cmd.Env = append(os.Environ(), helperEnv+"="+mode)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("helper %s: %v", mode, err)
+	}
+	return string(out), cmd.Process.Pid
+}
+
+func TestAcquireContention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+
+	lf, err := Acquire(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := helper(t, "acquire", path); out != context.DeadlineExceeded.Error() {
+		t.Fatalf("while held: child got %q", out)
+	}
+	if err := lf.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := helper(t, "acquire", path); out != "<nil>" {
+		t.Fatalf("after release: child got %q", out)
+	}
+}
+
+func TestStalePID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock")
+
+	_, crashed := helper(t, "crash", path)
+	pid, running, err := Holder(path)
+	if err != nil || pid != crashed || running {
+		t.Fatalf("Holder = %d, %t, %v, want %d, false, nil", pid, running, err, crashed)
+	}
+
+	lf, err := Acquire(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lf.Release()
+	if lf.StalePID != crashed {
+		t.Fatalf("StalePID = %d, want %d", lf.StalePID, crashed)
+	}
+	if pid, running, _ := Holder(path); pid != os.Getpid() || !running {
+		t.Fatalf("Holder = %d, %t, want self", pid, running)
+	}
+}
+
+func TestFlockAcrossProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err := Flock(context.Background(), f, Shared); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct{ mode, want string }{
+		{"shared", "true <nil>"},
+		{"exclusive", "false <nil>"},
+	} {
+		if out, _ := helper(t, c.mode, path); out != c.want {
+			t.Fatalf("%s while shared: child got %q, want %q", c.mode, out, c.want)
+		}
+	}
+
+	if err := Funlock(f); err != nil {
+		t.Fatal(err)
+	}
+	if out, _ := helper(t, "exclusive", path); out != "true <nil>" {
+		t.Fatalf("exclusive after unlock: child got %q", out)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := Flock(ctx, f, Exclusive); err != nil {
+		t.Fatal(err)
+	}
+	other, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	// flock locks conflict between open file descriptions even in a single process.
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := Flock(ctx, other, Shared); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+}
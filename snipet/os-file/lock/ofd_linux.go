@@ -0,0 +1,35 @@
+package lock
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// TryOFDLock is like OFDLock but never waits. It reports whether the lock is acquired.
+func TryOFDLock(f *os.File, mode Mode) (bool, error) {
+	typ := int16(unix.F_RDLCK)
+	if mode == Exclusive {
+		typ = unix.F_WRLCK
+	}
+	err := ofd(f, unix.F_OFD_SETLK, typ)
+	if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EACCES) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func OFDUnlock(f *os.File) error {
+	return ofd(f, unix.F_OFD_SETLK, unix.F_UNLCK)
+}
+
+func ofd(f *os.File, cmd int, typ int16) error {
+	// Len = 0 means the whole file, even when it grows.
+	lk := unix.Flock_t{
+		Type:   typ,
+		Whence: io.SeekStart,
+	}
+	return ignoringEINTR(func() error { return unix.FcntlFlock(f.Fd(), cmd, &lk) })
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package lock
+
+import (
+	"errors"
+	"os"
+)
+
+func TryOFDLock(f *os.File, mode Mode) (bool, error) {
+	return false, errors.ErrUnsupported
+}
+
+func OFDUnlock(f *os.File) error {
+	return errors.ErrUnsupported
+}
@@ -1,12 +1,20 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"io-copy-file/atomicfile"
 	"io/fs"
 	"os"
+	"os-file/lock"
+	"path/filepath"
 )
 
 func main() {
+	writeAtomically()
+	lockFile()
+
 	f, err := os.OpenFile("/path/to/file", os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_EXCL, fs.ModePerm)
 	if err != nil {
 		panic(err)
@@ -18,3 +26,78 @@ func main() {
 	var _ io.ReaderAt = f
 	var _ io.WriterAt = f
 }
+
+// writeAtomically replaces a file without ever exposing a half-written one,
+// unlike writing into the file opened by os.OpenFile directly.
+func writeAtomically() {
+	tmpDir, err := os.MkdirTemp("", "test-*")
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	path := filepath.Join(tmpDir, "file")
+	err = os.WriteFile(path, []byte("old"), 0o600)
+	if err != nil {
+		panic(err)
+	}
+
+	w, err := atomicfile.Create(path, atomicfile.Options{PreservePerm: true})
+	if err != nil {
+		panic(err)
+	}
+	defer w.Abort()
+
+	_, err = w.Write([]byte("new"))
+	if err != nil {
+		panic(err)
+	}
+	bin, _ := os.ReadFile(path)
+	fmt.Printf("before commit: %s\n", bin) // before commit: old
+
+	err = w.Commit()
+	if err != nil {
+		panic(err)
+	}
+	bin, _ = os.ReadFile(path)
+	info, _ := os.Stat(path)
+	fmt.Printf("after commit: %s, %s\n", bin, info.Mode()) // after commit: new, -rw-------
+}
+
+// lockFile takes a lock file, which other processes contend for by Acquire.
+// See lock/lock_test.go for contention between processes.
+func lockFile() {
+	tmpDir, err := os.MkdirTemp("", "test-*")
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	path := filepath.Join(tmpDir, "lock")
+
+	lf, err := lock.Acquire(context.Background(), path)
+	if err != nil {
+		panic(err)
+	}
+	defer lf.Release()
+	pid, running, _ := lock.Holder(path)
+	fmt.Printf("holder is self = %t, running = %t\n", pid == os.Getpid(), running) // holder is self = true, running = true
+
+	// OFD locks conflict between open file descriptions even in a single process,
+	// where classic POSIX record locks would not.
+	f1, err := os.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	defer f1.Close()
+	// write lock needs the file opened for writing.
+	f2, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		panic(err)
+	}
+	defer f2.Close()
+	if err := lock.OFDLock(context.Background(), f1, lock.Shared); err != nil {
+		panic(err)
+	}
+	ok, err := lock.TryOFDLock(f2, lock.Exclusive)
+	fmt.Printf("second OFD lock: acquired = %t, err = %v\n", ok, err) // second OFD lock: acquired = false, err = <nil>
+}